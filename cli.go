@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/gamedata/parser"
+	"github.com/wezzle/bar-unit-info/util"
+)
+
+type command struct {
+	description string
+	run         func(args []string) error
+}
+
+var commands = map[string]command{
+	"compare":      {"Print the properties of several units side by side", compareCommand},
+	"export":       {"Export the unit table", exportCommand},
+	"lint":         {"Check the unit definitions of a game repo or mod directory", lintCommand},
+	"parity":       {"Report faction parity for every grid and lab slot", parityCommand},
+	"search":       {"Search units by ref, name, description, unit group and weapon", searchCommand},
+	"show":         {"Print the properties of a unit by ref, name or alias", showCommand},
+	"translations": {"Report missing and orphaned unit translations per language", translationsCommand},
+	"tweaks":       {"List unit properties changed by tweakdefs and tweakunits", tweaksCommand},
+	"unknown-keys": {"Report unit definition keys the parser doesn't map, requires GAME_REPO", unknownKeysCommand},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [--lang code] [command] [flags]\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Without a command the interactive unit table is started.")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	names := make([]string, 0)
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", name, commands[name].description)
+	}
+}
+
+func writeRecords(w io.Writer, format string, header []string, records [][]string) error {
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(records); err != nil {
+			return err
+		}
+		return cw.Error()
+	case "markdown", "md":
+		escape := func(row []string) []string {
+			escaped := make([]string, len(row))
+			for i, v := range row {
+				escaped[i] = strings.ReplaceAll(v, "|", "\\|")
+			}
+			return escaped
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(escape(header), " | "))
+		separators := make([]string, len(header))
+		for i := range separators {
+			separators[i] = "---"
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(separators, " | "))
+		for _, r := range records {
+			fmt.Fprintf(w, "| %s |\n", strings.Join(escape(r), " | "))
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q, expected csv or markdown", format)
+}
+
+func parityCommand(args []string) error {
+	fs := flag.NewFlagSet("parity", flag.ExitOnError)
+	format := fs.String("format", "csv", "output format: csv or markdown")
+	fs.Parse(args)
+
+	return writeRecords(os.Stdout, *format, util.ParityHeader, util.Parity().Records())
+}
+
+func exportCommand(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "csv", "output format: csv or markdown")
+	includePvE := fs.Bool("include-pve", false, "include Scavenger and Raptor units")
+	tweaked := fs.Bool("tweaked", false, "use the unit data with tweakdefs and tweakunits applied")
+	fs.Parse(args)
+
+	// Leave the data loaded with the global --tweaks flag on unless -tweaked
+	// is given explicitly
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "tweaked" {
+			gamedata.UseTweakedUnitProperties(*tweaked)
+		}
+	})
+
+	return writeRecords(os.Stdout, *format, util.UnitHeader, util.UnitRecords(*includePvE))
+}
+
+func tweaksCommand(args []string) error {
+	fs := flag.NewFlagSet("tweaks", flag.ExitOnError)
+	format := fs.String("format", "csv", "output format: csv or markdown")
+	fs.Parse(args)
+
+	if !gamedata.HasTweaks() {
+		return fmt.Errorf("the unit data was generated without tweakdefs or tweakunits modoptions")
+	}
+	return writeRecords(os.Stdout, *format, util.TweakHeader, util.TweakRecords())
+}
+
+func unknownKeysCommand(args []string) error {
+	fs := flag.NewFlagSet("unknown-keys", flag.ExitOnError)
+	format := fs.String("format", "csv", "output format: csv or markdown")
+	examples := fs.Int("examples", 3, "number of example units per key")
+	fs.Parse(args)
+
+	if *examples < 0 {
+		return fmt.Errorf("--examples can't be negative")
+	}
+	if os.Getenv("GAME_REPO") == "" {
+		return fmt.Errorf("GAME_REPO must point to a checkout of the Beyond All Reason repo")
+	}
+	header := []string{"Scope", "Key", "Units", "Examples"}
+	records := make([][]string, 0)
+	for _, uk := range parser.FindUnknownKeys(*examples) {
+		records = append(records, []string{uk.Scope, uk.Key, strconv.Itoa(uk.Count), strings.Join(uk.Examples, " ")})
+	}
+	return writeRecords(os.Stdout, *format, header, records)
+}
+
+func lintCommand(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s lint <path>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// The parser reads the game repo from GAME_REPO, like go generate does
+	os.Setenv("GAME_REPO", fs.Arg(0))
+	problems, err := parser.Lint()
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problems", len(problems))
+	}
+	return nil
+}
+
+func translationsCommand(args []string) error {
+	fs := flag.NewFlagSet("translations", flag.ExitOnError)
+	format := fs.String("format", "text", "output format: text or json")
+	fs.Parse(args)
+
+	report := util.TranslationCoverageReport()
+	switch *format {
+	case "json":
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		return e.Encode(report)
+	case "text":
+		list := func(label string, refs []string) {
+			if len(refs) > 0 {
+				fmt.Printf("  %s (%d): %s\n", label, len(refs), strings.Join(refs, ", "))
+			}
+		}
+		for _, c := range report {
+			fmt.Printf("%s: %.1f%% covered\n", c.Language, c.Coverage)
+			list("missing names", c.MissingNames)
+			list("missing descriptions", c.MissingDescriptions)
+			list("orphaned", c.Orphaned)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q, expected text or json", *format)
+}
+
+func searchCommand(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	format := fs.String("format", "csv", "output format: csv or markdown")
+	includePvE := fs.Bool("include-pve", false, "include Scavenger and Raptor units")
+	limit := fs.Int("limit", 20, "maximum number of results, 0 for all")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("missing search query")
+	}
+
+	// Markdown highlights the matched text in bold
+	highlight := func(s string) string {
+		return s
+	}
+	if *format == "markdown" || *format == "md" {
+		highlight = func(s string) string {
+			return "**" + s + "**"
+		}
+	}
+
+	header := []string{"Ref", "Name", "Faction", "Score", "Matches"}
+	records := make([][]string, 0)
+	for i, r := range util.SearchUnits(strings.Join(fs.Args(), " "), *includePvE) {
+		if *limit > 0 && i >= *limit {
+			break
+		}
+		matches := make([]string, 0)
+		for _, m := range r.Matches {
+			matches = append(matches, fmt.Sprintf("%s: %s", m.Field, util.HighlightTerms(m.Text, r.Terms, highlight)))
+		}
+		records = append(records, []string{
+			r.Ref,
+			util.NameForRef(r.Ref),
+			util.FactionForRef(r.Ref),
+			strconv.FormatFloat(r.Score, 'f', 1, 64),
+			strings.Join(matches, "; "),
+		})
+	}
+	return writeRecords(os.Stdout, *format, header, records)
+}
+
+// resolveUnits returns the refs of units given by ref, translated name or
+// alias.
+func resolveUnits(names []string) ([]string, error) {
+	refs := make([]string, 0, len(names))
+	for _, name := range names {
+		ref, ok := util.ResolveUnit(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown unit %q", name)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func showCommand(args []string) error {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("missing unit")
+	}
+
+	refs, err := resolveUnits([]string{strings.Join(fs.Args(), " ")})
+	if err != nil {
+		return err
+	}
+	up, _ := gamedata.GetUnitPropertiesByRef(refs[0])
+	record := util.UnitRecord(refs[0], up)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for i, h := range util.UnitHeader {
+		fmt.Fprintf(w, "%s:\t%s\n", h, record[i])
+	}
+	if aliases := util.AliasesForRef(refs[0]); len(aliases) > 0 {
+		fmt.Fprintf(w, "Aliases:\t%s\n", strings.Join(aliases, ", "))
+	}
+	if d := util.DescriptionForRef(refs[0]); d != "" {
+		fmt.Fprintf(w, "Description:\t%s\n", d)
+	}
+	return w.Flush()
+}
+
+func compareCommand(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	format := fs.String("format", "csv", "output format: csv or markdown")
+	fs.Parse(args)
+	if fs.NArg() < 2 {
+		return fmt.Errorf("compare needs at least two units")
+	}
+
+	refs, err := resolveUnits(fs.Args())
+	if err != nil {
+		return err
+	}
+	records := make([][]string, 0, len(refs))
+	for _, ref := range refs {
+		up, _ := gamedata.GetUnitPropertiesByRef(ref)
+		records = append(records, util.UnitRecord(ref, up))
+	}
+	return writeRecords(os.Stdout, *format, util.UnitHeader, records)
+}
@@ -29,10 +29,13 @@
         url = "https://github.com/beyond-all-reason/Beyond-All-Reason.git";
         sparseCheckout = [
           "units"
-          "language/en"
+          "language"
           "luaui/configs"
+          "gamedata"
+          "common"
+          "weapons"
         ];
-        hash = "sha256-XXlFn5Cz1VRn3Arx4VIS4exEigxILqbrl1s8ZcgqPKc=";
+        hash = pkgs.lib.fakeHash;
       };
   in {
     # Provide some binary packages for selected system types.
@@ -11,9 +11,61 @@ import (
 	"text/template"
 
 	"github.com/wezzle/bar-unit-info/gamedata/parser"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
 )
 
+var (
+	unitProperties []types.UnitProperties
+	rawUnitDefs    map[types.UnitRef]types.RawUnitDef
+)
+
+func loadUnitProperties() []types.UnitProperties {
+	if unitProperties == nil {
+		unitProperties, rawUnitDefs = parser.LoadUnitDefs()
+	}
+	return unitProperties
+}
+
+func loadRawUnitDefs() map[types.UnitRef]types.RawUnitDef {
+	loadUnitProperties()
+	return rawUnitDefs
+}
+
+var tweakedUnitProperties []types.UnitProperties
+
+func loadTweakedUnitProperties() []types.UnitProperties {
+	if tweakedUnitProperties == nil && parser.HasTweaks() {
+		tweakedUnitProperties = parser.LoadAllTweakedUnitProperties()
+	}
+	return tweakedUnitProperties
+}
+
 func main() {
+	// go:generate can't pass arguments through, the modoptions are read from
+	// the environment
+	modOptionValues := make([]string, 0)
+	if env := os.Getenv("MODOPTIONS"); env != "" {
+		modOptionValues = strings.Split(env, ",")
+	}
+
+	modOptions := make(types.ModOptions)
+	if modOptionsFile := os.Getenv("MODOPTIONS_FILE"); modOptionsFile != "" {
+		mo, err := parser.LoadModOptionsFile(modOptionsFile)
+		if err != nil {
+			panic(err)
+		}
+		modOptions = mo
+	}
+	for _, v := range modOptionValues {
+		key, value, err := parser.ParseModOption(v)
+		if err != nil {
+			panic(err)
+		}
+		modOptions[key] = value
+	}
+	parser.SetModOptions(modOptions)
+	parser.SetIncludePvE(os.Getenv("PVE_UNITS") != "")
+
 	templates, err := filepath.Glob("templates/*.go.tmpl")
 	if err != nil {
 		panic(err)
@@ -46,12 +98,41 @@ func main() {
 			unitGrid, _ := parser.LoadGridLayouts()
 			data.Var = fmt.Sprintf("%#v\n", unitGrid)
 		case "unitproperties.go":
-			unitProperties := parser.LoadAllUnitProperties()
+			unitProperties := loadUnitProperties()
 			data.Len = len(unitProperties)
 			data.Var = strings.Replace(fmt.Sprintf("%#v\n", unitProperties), "[]types.UnitProperties{", fmt.Sprintf("[%d]types.UnitProperties{", data.Len), 1)
+		case "tweakedunitproperties.go":
+			tweaked := make(map[string]types.UnitProperties)
+			if parser.HasTweaks() {
+				stock := make(map[string]types.UnitProperties)
+				for _, up := range loadUnitProperties() {
+					stock[up.Ref] = up
+				}
+				for _, up := range loadTweakedUnitProperties() {
+					if s, ok := stock[up.Ref]; ok && len(types.DiffUnitProperties(&s, &up)) == 0 {
+						continue
+					}
+					tweaked[up.Ref] = up
+				}
+			}
+			data.Var = fmt.Sprintf("%#v\n", tweaked)
+		case "weapondefs.go":
+			referenced := make(map[string]types.WeaponDef)
+			weaponDefs := parser.LoadWeaponDefs()
+			for _, up := range append(loadUnitProperties(), loadTweakedUnitProperties()...) {
+				for _, name := range []string{up.ExplodeAs, up.SelfDestructAs} {
+					if wd, ok := weaponDefs[name]; ok {
+						referenced[name] = wd
+					}
+				}
+			}
+			data.Var = fmt.Sprintf("%#v\n", referenced)
+		case "rawunitdefs.go":
+			data.Var = fmt.Sprintf("%#v\n", loadRawUnitDefs())
+		case "modoptions.go":
+			data.Var = fmt.Sprintf("%#v\n", parser.GetModOptions())
 		case "translations.go":
-			t := parser.LoadTranslations("en")
-			data.Var = fmt.Sprintf("%#v\n", t)
+			data.Var = fmt.Sprintf("%#v\n", parser.LoadAllTranslations())
 		}
 
 		tpl.Execute(f, data)
@@ -0,0 +1,124 @@
+// Package luaenv provides a Lua state that mimics the Spring engine
+// environment game scripts such as unit definitions, grid layouts and def
+// post-processing expect.
+package luaenv
+
+import (
+	"io/fs"
+	"sort"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+type Env struct {
+	L *lua.LState
+
+	repo       fs.FS
+	modOptions map[string]string
+	gametype   map[string]bool
+	// missing counts accesses to globals and Spring/VFS members that aren't
+	// implemented by the environment
+	missing map[string]int
+}
+
+type Option func(*Env)
+
+// WithModOptions sets the values returned by Spring.GetModOptions.
+func WithModOptions(mo map[string]string) Option {
+	return func(e *Env) {
+		e.modOptions = mo
+	}
+}
+
+// WithGametype sets the result of a Spring.Utilities.Gametype check, e.g.
+// WithGametype("IsScavengers", true).
+func WithGametype(check string, value bool) Option {
+	return func(e *Env) {
+		e.gametype[check] = value
+	}
+}
+
+// New returns an environment that resolves VFS calls against repo.
+func New(repo fs.FS, opts ...Option) *Env {
+	e := &Env{
+		L:          lua.NewState(),
+		repo:       repo,
+		modOptions: make(map[string]string),
+		gametype:   make(map[string]bool),
+		missing:    make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	e.L.SetGlobal("Spring", e.springTable())
+	e.L.SetGlobal("VFS", e.vfsTable())
+	e.L.SetGlobal("lowerkeys", e.L.NewFunction(lowerkeys))
+	e.L.SetGlobal("WeaponDefs", e.L.NewTable())
+	e.track(e.L.G.Global, "")
+	return e
+}
+
+func (e *Env) Close() {
+	e.L.Close()
+}
+
+// track records reads of keys missing from t. Missing keys still evaluate to
+// nil, so scripts keep running the same way they would without tracking.
+func (e *Env) track(t *lua.LTable, prefix string) {
+	mt := e.L.NewTable()
+	mt.RawSetString("__index", e.L.NewFunction(func(L *lua.LState) int {
+		if k, ok := L.Get(2).(lua.LString); ok {
+			e.missing[prefix+string(k)]++
+		}
+		L.Push(lua.LNil)
+		return 1
+	}))
+	e.L.SetMetatable(t, mt)
+}
+
+// Missing returns the names of unimplemented globals and Spring/VFS members
+// scripts tried to use, sorted by name.
+func (e *Env) Missing() []string {
+	names := make([]string, 0)
+	for name := range e.missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MissingCount returns how often a missing name was accessed.
+func (e *Env) MissingCount(name string) int {
+	return e.missing[name]
+}
+
+// DoFile runs a file from the repo.
+func (e *Env) DoFile(path string) error {
+	fn, err := e.loadFile(path)
+	if err != nil {
+		return err
+	}
+	e.L.Push(fn)
+	return e.L.PCall(0, lua.MultRet, nil)
+}
+
+func (e *Env) loadFile(path string) (*lua.LFunction, error) {
+	content, err := fs.ReadFile(e.repo, repoPath(path))
+	if err != nil {
+		return nil, err
+	}
+	return e.L.Load(strings.NewReader(string(content)), path)
+}
+
+// repoPath turns a path as used in game scripts into a path valid for fs.FS.
+func repoPath(path string) string {
+	return strings.TrimPrefix(strings.ReplaceAll(path, "\\", "/"), "/")
+}
+
+// FileExists reports whether path exists in the repo.
+func (e *Env) FileExists(path string) bool {
+	_, err := fs.Stat(e.repo, repoPath(path))
+	return err == nil
+}
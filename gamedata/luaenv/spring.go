@@ -0,0 +1,208 @@
+package luaenv
+
+import (
+	"encoding/base64"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// gametypeChecks are the Spring.Utilities.Gametype functions, all of them
+// return false unless set with WithGametype.
+var gametypeChecks = []string{
+	"IsScavengers", "IsRaptors", "IsPvE", "IsCoop", "IsFFA", "IsTeams",
+	"Is1v1", "IsSinglePlayer", "IsSandbox", "IsAITeam",
+}
+
+func (e *Env) springTable() *lua.LTable {
+	L := e.L
+	spring := L.NewTable()
+	spring.RawSetString("GetModOptions", L.NewFunction(e.getModOptions))
+	spring.RawSetString("Echo", L.NewFunction(echo))
+	spring.RawSetString("Log", L.NewFunction(echo))
+	// Rules params and configs don't exist while loading defs, return nil or
+	// the provided default
+	spring.RawSetString("GetTeamRulesParam", L.NewFunction(returnNil))
+	spring.RawSetString("GetGameRulesParam", L.NewFunction(returnNil))
+	spring.RawSetString("GetConfigInt", L.NewFunction(returnDefault))
+	spring.RawSetString("GetConfigFloat", L.NewFunction(returnDefault))
+	spring.RawSetString("GetConfigString", L.NewFunction(returnDefault))
+	spring.RawSetString("Utilities", e.utilitiesTable())
+	e.track(spring, "Spring.")
+	return spring
+}
+
+func (e *Env) utilitiesTable() *lua.LTable {
+	L := e.L
+	gametype := L.NewTable()
+	for _, check := range gametypeChecks {
+		value := e.gametype[check]
+		gametype.RawSetString(check, L.NewFunction(func(L *lua.LState) int {
+			L.Push(lua.LBool(value))
+			return 1
+		}))
+	}
+	e.track(gametype, "Spring.Utilities.Gametype.")
+
+	utilities := L.NewTable()
+	utilities.RawSetString("Gametype", gametype)
+	utilities.RawSetString("CopyTable", L.NewFunction(copyTable))
+	utilities.RawSetString("MergeTable", L.NewFunction(mergeTable))
+	utilities.RawSetString("Base64Decode", L.NewFunction(base64Decode))
+	utilities.RawSetString("Base64Encode", L.NewFunction(base64Encode))
+	utilities.RawSetString("CustomKeyToUsefulTable", L.NewFunction(customKeyToUsefulTable))
+	e.track(utilities, "Spring.Utilities.")
+	return utilities
+}
+
+func (e *Env) getModOptions(L *lua.LState) int {
+	t := L.NewTable()
+	for k, v := range e.modOptions {
+		t.RawSetString(k, modOptionValue(v))
+	}
+	L.Push(t)
+	return 1
+}
+
+// modOptionValue converts a modoption to the Lua type scripts expect.
+func modOptionValue(v string) lua.LValue {
+	if v == "true" || v == "false" {
+		return lua.LBool(v == "true")
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return lua.LNumber(f)
+	}
+	return lua.LString(v)
+}
+
+func echo(L *lua.LState) int {
+	args := make([]string, 0)
+	for i := 1; i <= L.GetTop(); i++ {
+		args = append(args, L.Get(i).String())
+	}
+	slog.Debug("Spring.Echo", "message", strings.Join(args, " "))
+	return 0
+}
+
+func returnNil(L *lua.LState) int {
+	L.Push(lua.LNil)
+	return 1
+}
+
+func returnDefault(L *lua.LState) int {
+	L.Push(L.Get(2))
+	return 1
+}
+
+// lowerkeys lowercases all string keys of a table in place, recursively, like
+// the function the engine provides to def scripts.
+func lowerkeys(L *lua.LState) int {
+	t := L.CheckTable(1)
+	LowerKeys(t)
+	L.Push(t)
+	return 1
+}
+
+// LowerKeys lowercases all string keys of t in place, recursively.
+func LowerKeys(t *lua.LTable) {
+	changed := make(map[string]lua.LValue)
+	t.ForEach(func(k lua.LValue, v lua.LValue) {
+		if sub, ok := v.(*lua.LTable); ok {
+			LowerKeys(sub)
+		}
+		if k.Type() == lua.LTString && strings.ToLower(k.String()) != k.String() {
+			changed[k.String()] = v
+		}
+	})
+	for k, v := range changed {
+		t.RawSetString(k, lua.LNil)
+		t.RawSetString(strings.ToLower(k), v)
+	}
+}
+
+// copyTables returns a copy of t, nested tables are copied when deep is set.
+func copyTables(L *lua.LState, t *lua.LTable, deep bool) *lua.LTable {
+	c := L.NewTable()
+	t.ForEach(func(k lua.LValue, v lua.LValue) {
+		if sub, ok := v.(*lua.LTable); ok && deep {
+			v = copyTables(L, sub, deep)
+		}
+		c.RawSet(k, v)
+	})
+	return c
+}
+
+func copyTable(L *lua.LState) int {
+	L.Push(copyTables(L, L.CheckTable(1), L.OptBool(2, false)))
+	return 1
+}
+
+// mergeTable returns a new table with the values of secondary overwritten by
+// those of primary.
+func mergeTable(L *lua.LState) int {
+	L.Push(mergeTables(L, L.CheckTable(1), L.CheckTable(2), L.OptBool(3, false)))
+	return 1
+}
+
+func mergeTables(L *lua.LState, primary *lua.LTable, secondary *lua.LTable, deep bool) *lua.LTable {
+	merged := copyTables(L, secondary, deep)
+	primary.ForEach(func(k lua.LValue, v lua.LValue) {
+		sub, isTable := v.(*lua.LTable)
+		existing, existingIsTable := merged.RawGet(k).(*lua.LTable)
+		if deep && isTable && existingIsTable {
+			v = mergeTables(L, sub, existing, deep)
+		}
+		merged.RawSet(k, v)
+	})
+	return merged
+}
+
+// DecodeBase64 decodes strings in the standard and URL alphabets, with or
+// without padding, as found in lobby modoptions.
+func DecodeBase64(encoded string) (string, bool) {
+	encoded = strings.Join(strings.Fields(encoded), "")
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		decoded, err := encoding.DecodeString(encoded)
+		if err == nil {
+			return string(decoded), true
+		}
+	}
+	return "", false
+}
+
+func base64Decode(L *lua.LState) int {
+	decoded, ok := DecodeBase64(L.CheckString(1))
+	if !ok {
+		L.Push(lua.LNil)
+		return 1
+	}
+	L.Push(lua.LString(decoded))
+	return 1
+}
+
+func base64Encode(L *lua.LState) int {
+	L.Push(lua.LString(base64.StdEncoding.EncodeToString([]byte(L.CheckString(1)))))
+	return 1
+}
+
+// customKeyToUsefulTable decodes a base64 encoded Lua table as used for
+// modoptions such as tweakunits.
+func customKeyToUsefulTable(L *lua.LState) int {
+	decoded, ok := DecodeBase64(L.CheckString(1))
+	if !ok {
+		L.Push(lua.LNil)
+		return 1
+	}
+	fn, err := L.LoadString("return " + decoded)
+	if err != nil {
+		L.Push(lua.LNil)
+		return 1
+	}
+	L.Push(fn)
+	if err := L.PCall(0, 1, nil); err != nil {
+		L.Push(lua.LNil)
+	}
+	return 1
+}
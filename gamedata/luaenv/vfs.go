@@ -0,0 +1,98 @@
+package luaenv
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+func (e *Env) vfsTable() *lua.LTable {
+	L := e.L
+	vfs := L.NewTable()
+	// Everything is read from the repo, the modes only exist so scripts
+	// passing them don't hit missing globals
+	for _, mode := range []string{"RAW", "ZIP", "RAW_FIRST", "ZIP_FIRST", "MOD", "MAP", "BASE", "RAW_ONLY", "ZIP_ONLY"} {
+		vfs.RawSetString(mode, lua.LString(mode))
+	}
+	vfs.RawSetString("Include", L.NewFunction(e.vfsInclude))
+	vfs.RawSetString("LoadFile", L.NewFunction(e.vfsLoadFile))
+	vfs.RawSetString("FileExists", L.NewFunction(e.vfsFileExists))
+	vfs.RawSetString("DirList", L.NewFunction(e.vfsDirList))
+	vfs.RawSetString("SubDirs", L.NewFunction(e.vfsSubDirs))
+	e.track(vfs, "VFS.")
+	return vfs
+}
+
+// vfsInclude runs a file from the game repo and returns its results. An
+// optional environment table is used as the globals of the included chunk.
+func (e *Env) vfsInclude(L *lua.LState) int {
+	p := L.CheckString(1)
+	fn, err := e.loadFile(p)
+	if err != nil {
+		L.RaiseError("VFS.Include: %s", err)
+		return 0
+	}
+	if env, ok := L.Get(2).(*lua.LTable); ok {
+		L.SetFEnv(fn, env)
+	}
+	top := L.GetTop()
+	L.Push(fn)
+	L.Call(0, lua.MultRet)
+	return L.GetTop() - top
+}
+
+func (e *Env) vfsLoadFile(L *lua.LState) int {
+	content, err := fs.ReadFile(e.repo, repoPath(L.CheckString(1)))
+	if err != nil {
+		L.Push(lua.LNil)
+		return 1
+	}
+	L.Push(lua.LString(content))
+	return 1
+}
+
+func (e *Env) vfsFileExists(L *lua.LState) int {
+	L.Push(lua.LBool(e.FileExists(L.CheckString(1))))
+	return 1
+}
+
+// vfsDirList lists the files of a directory matching the optional pattern,
+// the paths are returned including the directory like the engine does.
+func (e *Env) vfsDirList(L *lua.LState) int {
+	L.Push(e.dirEntries(L.CheckString(1), L.OptString(2, "*"), false))
+	return 1
+}
+
+func (e *Env) vfsSubDirs(L *lua.LState) int {
+	L.Push(e.dirEntries(L.CheckString(1), L.OptString(2, "*"), true))
+	return 1
+}
+
+func (e *Env) dirEntries(dir string, pattern string, dirs bool) *lua.LTable {
+	t := e.L.NewTable()
+	entries, err := fs.ReadDir(e.repo, path.Clean(repoPath(dir)))
+	if err != nil {
+		return t
+	}
+	names := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() != dirs {
+			continue
+		}
+		if ok, _ := path.Match(pattern, entry.Name()); !ok {
+			continue
+		}
+		name := path.Join(repoPath(dir), entry.Name())
+		if dirs {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t.Append(lua.LString(name))
+	}
+	return t
+}
@@ -0,0 +1,241 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const gridLayoutsFile = "luaui/configs/gridmenu_layouts.lua"
+
+// Problem is an issue found in the unit definitions of the game repo. Line is
+// 0 when the problem couldn't be traced to a line.
+type Problem struct {
+	File    string
+	Line    int
+	Ref     types.UnitRef
+	Message string
+}
+
+func (p Problem) String() string {
+	location := p.File
+	if p.Line > 0 {
+		location = fmt.Sprintf("%s:%d", p.File, p.Line)
+	}
+	if p.Ref == "" {
+		return fmt.Sprintf("%s: %s", location, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, p.Ref, p.Message)
+}
+
+// linter finds problems and the lines they're on, the files are read once.
+type linter struct {
+	lines    map[string][]string
+	problems []Problem
+}
+
+// line returns the first line of file matching pattern, starting at the line
+// from.
+func (l *linter) line(file string, from int, pattern string) int {
+	if _, ok := l.lines[file]; !ok {
+		content, _ := os.ReadFile(file)
+		l.lines[file] = strings.Split(string(content), "\n")
+	}
+	re := regexp.MustCompile(pattern)
+	for i := max(from-1, 0); i < len(l.lines[file]); i++ {
+		if re.MatchString(l.lines[file][i]) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// keyLine returns the line key is assigned on in file, starting at the line
+// from.
+func (l *linter) keyLine(file string, from int, key string) int {
+	return l.line(file, from, `(?i)(^|[\s{,])\[?"?`+regexp.QuoteMeta(key)+`"?\]?\s*=`)
+}
+
+// stringLine returns the line of the quoted value in file, starting at the line
+// from.
+func (l *linter) stringLine(file string, from int, value string) int {
+	return l.line(file, from, `(?i)["']`+regexp.QuoteMeta(value)+`["']`)
+}
+
+func (l *linter) report(file string, line int, ref types.UnitRef, format string, args ...any) {
+	l.problems = append(l.problems, Problem{
+		File:    repoRelativePath(file),
+		Line:    line,
+		Ref:     ref,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// luaErrorLocation matches the chunk name and line gopher-lua prefixes runtime
+// and syntax errors with.
+var luaErrorLocation = regexp.MustCompile(`^\S+?(?::(\d+):| line:(\d+)\(column:\d+\))\s*`)
+
+// luaError splits an error evaluating a Lua chunk into the line it occurred on
+// and the message without the stack traceback. Line is 0 when the error has
+// no location.
+func luaError(err error) (int, string) {
+	msg, _, _ := strings.Cut(err.Error(), "\n")
+	m := luaErrorLocation.FindStringSubmatch(msg)
+	if m == nil {
+		return 0, msg
+	}
+	line, _ := strconv.Atoi(m[1] + m[2])
+	return line, strings.Join(strings.Fields(msg[len(m[0]):]), " ")
+}
+
+// tableLine returns the line a weapon or weapon definition starts on.
+func (l *linter) tableLine(st scopedTable) int {
+	switch st.scope {
+	case ScopeWeapon:
+		return l.keyLine(st.file, 0, "weapons")
+	case ScopeWeaponDef:
+		return l.keyLine(st.file, l.keyLine(st.file, 0, "weapondefs"), st.name)
+	}
+	return 0
+}
+
+// Lint evaluates the unit definitions of the game repo and reports unit files
+// that fail to evaluate, references to missing units and weapons, weapons
+// without a reload time, units without an English name or description and
+// values of the wrong type. Files that don't exist in the repo, like the grid
+// layouts in a mod directory, are skipped.
+func Lint() ([]Problem, error) {
+	env := newLuaEnv()
+	defer env.Close()
+
+	defs, unitDefs, err := evaluateUnitDefs(env, false)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(map[types.UnitRef]bool)
+	unitDefs.ForEach(func(k lua.LValue, _ lua.LValue) {
+		refs[k.String()] = true
+	})
+
+	var names, descriptions map[types.UnitRef]string
+	if env.FileExists("language/en/units.json") {
+		units := LoadTranslations("en").Units
+		names, descriptions = units.Names, units.Descriptions
+	}
+
+	l := &linter{lines: make(map[string][]string)}
+	for _, def := range defs {
+		if def.err != nil {
+			line, msg := luaError(def.err)
+			l.report(def.file, line, def.ref, "failed to evaluate: %s", msg)
+		}
+	}
+
+	rec := newRecorder()
+	tables := collectTables(defs, unitDefs)
+	for _, st := range tables {
+		if st.scope != ScopeUnit {
+			continue
+		}
+		up, err := readUnitProperties(&LuaTableParser{data: st.table, rec: rec}, st.ref)
+		if err != nil {
+			l.report(st.file, 0, st.ref, "failed to parse: %s", err)
+			continue
+		}
+		for _, bo := range up.BuildOptions {
+			if !refs[bo] {
+				l.report(st.file, l.stringLine(st.file, l.keyLine(st.file, 0, "buildoptions"), bo), st.ref, "build option %q has no unit definition", bo)
+			}
+		}
+		weaponsLine := l.keyLine(st.file, 0, "weapons")
+		referenced := make(map[string]bool)
+		for _, w := range up.Weapons {
+			name := strings.ToLower(w.Def)
+			if _, ok := up.WeaponDefs[name]; !ok {
+				l.report(st.file, l.stringLine(st.file, weaponsLine, w.Def), st.ref, "weapon def %q doesn't match any key in weapondefs", w.Def)
+				continue
+			}
+			referenced[name] = true
+		}
+		weaponDefsLine := l.keyLine(st.file, 0, "weapondefs")
+		for name, wd := range up.WeaponDefs {
+			if referenced[name] && wd.ReloadTime <= 0 {
+				l.report(st.file, l.keyLine(st.file, weaponDefsLine, name), st.ref, "weapon def %q has no reloadtime, DPS divides by it", name)
+			}
+		}
+		// Scavenger variants share the translations of the unit they're based on
+		base := strings.TrimSuffix(st.ref, "_scav")
+		if names != nil && names[st.ref] == "" && names[base] == "" {
+			l.report(st.file, 0, st.ref, "no name in language/en/units.json")
+		} else if descriptions != nil && descriptions[st.ref] == "" && descriptions[base] == "" {
+			l.report(st.file, 0, st.ref, "no description in language/en/units.json")
+		}
+	}
+
+	for _, st := range tables {
+		from := l.tableLine(st)
+		for key, err := range rec.typeErrors[st.table] {
+			if st.scope == ScopeUnit {
+				l.report(st.file, l.keyLine(st.file, from, key), st.ref, "%s: %s", key, err)
+			} else {
+				l.report(st.file, l.keyLine(st.file, from, key), st.ref, "%s %s: %s: %s", st.scope, st.name, key, err)
+			}
+		}
+	}
+
+	if env.FileExists(gridLayoutsFile) {
+		l.lintGridLayouts(refs)
+	}
+
+	sort.Slice(l.problems, func(i, j int) bool {
+		a, b := l.problems[i], l.problems[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Message < b.Message
+	})
+	return l.problems, nil
+}
+
+// lintGridLayouts reports grid menu entries without a unit definition.
+func (l *linter) lintGridLayouts(refs map[types.UnitRef]bool) {
+	file := filepath.Join(os.Getenv("GAME_REPO"), gridLayoutsFile)
+	seen := make(map[types.UnitRef]bool)
+	check := func(ref types.UnitRef) {
+		if ref != "" && !refs[ref] && !seen[ref] {
+			seen[ref] = true
+			l.report(file, l.stringLine(file, 0, ref), "", "grid entry %q has no unit definition", ref)
+		}
+	}
+
+	unitGrid, labGrid := LoadGridLayouts()
+	for constructor, group := range unitGrid {
+		check(constructor)
+		for _, row := range group {
+			for _, col := range row {
+				for _, ref := range col {
+					check(ref)
+				}
+			}
+		}
+	}
+	for lab, row := range labGrid {
+		check(lab)
+		for _, col := range row {
+			for _, ref := range col {
+				check(ref)
+			}
+		}
+	}
+}
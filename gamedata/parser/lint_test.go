@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// writeRepo creates a game repo with files, keyed by their repo relative path,
+// and points GAME_REPO at it.
+func writeRepo(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("GAME_REPO", dir)
+}
+
+const lintTestUnit = `return {
+	armpw = {
+		metalcost = 50,
+		health = 300,
+		buildoptions = {},
+	},
+}
+`
+
+func TestLintTranslations(t *testing.T) {
+	tests := []struct {
+		name  string
+		units string
+		want  []string
+	}{
+		{
+			name:  "translated",
+			units: `{"units": {"names": {"armpw": "Pawn"}, "descriptions": {"armpw": "Fast Infantry Bot"}}}`,
+			want:  []string{},
+		},
+		{
+			name:  "missing name",
+			units: `{"units": {"names": {}, "descriptions": {"armpw": "Fast Infantry Bot"}}}`,
+			want:  []string{"units/ArmBots/armpw.lua: armpw: no name in language/en/units.json"},
+		},
+		{
+			name:  "empty name",
+			units: `{"units": {"names": {"armpw": ""}, "descriptions": {}}}`,
+			want:  []string{"units/ArmBots/armpw.lua: armpw: no name in language/en/units.json"},
+		},
+		{
+			name:  "missing description",
+			units: `{"units": {"names": {"armpw": "Pawn"}, "descriptions": {}}}`,
+			want:  []string{"units/ArmBots/armpw.lua: armpw: no description in language/en/units.json"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeRepo(t, map[string]string{
+				"units/ArmBots/armpw.lua": lintTestUnit,
+				"language/en/units.json":  tt.units,
+			})
+			problems, err := Lint()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0)
+			for _, p := range problems {
+				got = append(got, p.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintScavengerTranslations(t *testing.T) {
+	writeRepo(t, map[string]string{
+		"units/ArmBots/armpw.lua":         lintTestUnit,
+		"units/Scavengers/armpw_scav.lua": `return { armpw_scav = { metalcost = 50, health = 300 } }`,
+		"language/en/units.json":          `{"units": {"names": {"armpw": "Pawn"}, "descriptions": {"armpw": "Fast Infantry Bot"}}}`,
+	})
+	problems, err := Lint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Lint() = %q, want no problems for a Scavenger variant", problems)
+	}
+}
+
+func TestLuaError(t *testing.T) {
+	tests := []struct {
+		name     string
+		chunk    string
+		wantLine int
+		wantMsg  string
+	}{
+		{"syntax error", "return {\n\tarmpw = {\n\t\thealth = ,\n\t},\n}", 3, "near ',': syntax error"},
+		{"runtime error", "local x = nil\nreturn x.y", 2, "attempt to index a non-table object(nil) with key 'y'"},
+		{"error call", "error('broken unit')", 1, "broken unit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			L := lua.NewState()
+			defer L.Close()
+			fn, err := L.Load(strings.NewReader(tt.chunk), "units/ArmBots/armpw.lua")
+			if err == nil {
+				L.Push(fn)
+				err = L.PCall(0, lua.MultRet, nil)
+			}
+			if err == nil {
+				t.Fatal("chunk evaluated without an error")
+			}
+			line, msg := luaError(err)
+			if line != tt.wantLine || msg != tt.wantMsg {
+				t.Errorf("luaError(%q) = %d, %q, want %d, %q", err, line, msg, tt.wantLine, tt.wantMsg)
+			}
+		})
+	}
+}
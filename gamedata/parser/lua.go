@@ -6,10 +6,12 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/mattn/go-zglob"
+	"github.com/wezzle/bar-unit-info/gamedata/luaenv"
 	"github.com/wezzle/bar-unit-info/gamedata/types"
 	lua "github.com/yuin/gopher-lua"
 )
@@ -22,16 +24,41 @@ func IgnoreError[T any](key string, f func(string) (T, error)) T {
 	return v
 }
 
-func isScavengers(L *lua.LState) int {
-	L.Push(lua.LFalse)
-	return 1
+// newLuaEnv returns a Spring like Lua environment reading from the game repo.
+func newLuaEnv() *luaenv.Env {
+	return luaenv.New(os.DirFS(os.Getenv("GAME_REPO")), luaenv.WithModOptions(luaModOptions()))
 }
 
-func getModOptions(L *lua.LState) int {
-	t := lua.LTable{}
-	t.RawSetString("forceallunits", lua.LFalse)
-	L.Push(&t)
-	return 1
+// includePvE enables the PvE evaluation pass, it takes as long as the regular
+// one.
+var includePvE bool
+
+// SetIncludePvE sets whether the definitions are evaluated a second time as a
+// PvE game to add the Scavenger and Raptor units only generated there.
+func SetIncludePvE(include bool) {
+	includePvE = include
+}
+
+// newPvELuaEnv works like newLuaEnv for a Scavengers and Raptors game with the
+// forceallunits modoption set, so the game's post-processing generates the
+// Scavenger variants and Raptor units.
+func newPvELuaEnv() *luaenv.Env {
+	mo := luaModOptions()
+	mo["forceallunits"] = "true"
+	return luaenv.New(os.DirFS(os.Getenv("GAME_REPO")),
+		luaenv.WithModOptions(mo),
+		luaenv.WithGametype("IsScavengers", true),
+		luaenv.WithGametype("IsRaptors", true),
+		luaenv.WithGametype("IsPvE", true),
+	)
+}
+
+// reportMissing logs the globals and Spring/VFS members scripts used that the
+// environment doesn't implement, results may be incomplete when any are listed.
+func reportMissing(env *luaenv.Env, stage string) {
+	for _, name := range env.Missing() {
+		slog.Warn("lua environment is missing a global", "stage", stage, "name", name, "count", env.MissingCount(name))
+	}
 }
 
 func indexFromLValue(v lua.LValue) int {
@@ -103,58 +130,203 @@ func LoadGridLayouts() (types.UnitGrid, types.LabGrid) {
 		panic(err)
 	}
 
-	L := lua.NewState()
-
-	springTable := lua.LTable{}
-	utilitiesTable := lua.LTable{}
-	gametypeTable := lua.LTable{}
-	gametypeTable.RawSetString("IsScavengers", L.NewFunction(isScavengers))
-	utilitiesTable.RawSetString("Gametype", &gametypeTable)
-	springTable.RawSetString("Utilities", &utilitiesTable)
-	springTable.RawSetString("GetModOptions", L.NewFunction(getModOptions))
-	L.SetGlobal("Spring", &springTable)
-	defer L.Close()
-	if err := L.DoString(string(fileContents)); err != nil {
+	env := newLuaEnv()
+	defer env.Close()
+	if err := env.L.DoString(string(fileContents)); err != nil {
 		panic(err)
 	}
+	reportMissing(env, "grid layouts")
 
-	lv := L.Get(-1).(*lua.LTable)
+	lv := env.L.Get(-1).(*lua.LTable)
 
 	unitGrid := loadUnitGrid(lv.RawGetString("UnitGrids").(*lua.LTable))
 	labGrid := loadLabGrid(lv.RawGetString("LabGrids").(*lua.LTable))
 	return unitGrid, labGrid
 }
 
-func LoadAllUnitProperties() []types.UnitProperties {
-	_, labGrid := LoadGridLayouts()
+// unitDef is an evaluated unit definition and the file it was loaded from.
+// Err is set when the file failed to read or evaluate, the unit is then
+// missing from UnitDefs.
+type unitDef struct {
+	ref  string
+	file string
+	err  error
+}
 
-	unitProperties := make([]types.UnitProperties, 0)
-	// upByRef := make(types.UnitPropertiesByRef)
+// loadUnitDefs evaluates every unit file in L and collects the unit tables in
+// the UnitDefs global, the same way the game exposes them to post-processing.
+func loadUnitDefs(L *lua.LState) ([]unitDef, *lua.LTable, error) {
 	files, err := zglob.Glob(fmt.Sprintf("%s/units/**/*.lua", os.Getenv("GAME_REPO")))
 	if err != nil {
-		slog.Error("failed to glob", "error", "err")
-		return nil
+		return nil, nil, err
 	}
+
+	defs := make([]unitDef, 0)
+	unitDefs := L.NewTable()
+	L.SetGlobal("UnitDefs", unitDefs)
 	for _, f := range files {
 		ref := strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
 		content, err := os.ReadFile(f)
 		if err != nil {
 			slog.Error("failed to read file", "file", f)
+			defs = append(defs, unitDef{ref: ref, file: f, err: err})
 			continue
 		}
-		up, err := parseUnitProperties(string(content), ref)
+		t, err := evaluateUnitFile(L, string(content), repoRelativePath(f), ref)
 		if err != nil {
-			slog.Error("failed to parse unit properties", "ref", ref)
+			slog.Error("failed to evaluate unit file", "file", f, "error", err)
+			defs = append(defs, unitDef{ref: ref, file: f, err: err})
 			continue
 		}
+		unitDefs.RawSetString(ref, t)
+		defs = append(defs, unitDef{ref: ref, file: f})
+	}
+	return defs, unitDefs, nil
+}
+
+// evaluateUnitDefs loads every unit file into env and runs the game's
+// post-processing and then the tweaks over them. Tweaks loaded at runtime are
+// applied to the post-processed definitions as well, so both give the same
+// values.
+func evaluateUnitDefs(env *luaenv.Env, applyTweaks bool) ([]unitDef, *lua.LTable, error) {
+	defs, unitDefs, err := loadUnitDefs(env.L)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ApplyPostProcessing(env); err != nil {
+		slog.Error("failed to post-process unit definitions", "error", err)
+	}
+	if applyTweaks {
+		if err := ApplyTweaks(env.L, unitDefs); err != nil {
+			slog.Error("failed to apply tweaks", "error", err)
+		}
+	}
+	return defs, unitDefs, nil
+}
+
+func LoadAllUnitProperties() []types.UnitProperties {
+	unitProperties, _ := loadAllUnitProperties(false)
+	return unitProperties
+}
+
+// LoadAllTweakedUnitProperties works like LoadAllUnitProperties but applies
+// the tweakdefs and tweakunits modoptions before extracting properties.
+func LoadAllTweakedUnitProperties() []types.UnitProperties {
+	unitProperties, _ := loadAllUnitProperties(true)
+	return unitProperties
+}
+
+// LoadUnitDefs works like LoadAllUnitProperties and also returns the full
+// evaluated definition of every unit by ref.
+func LoadUnitDefs() ([]types.UnitProperties, map[types.UnitRef]types.RawUnitDef) {
+	return loadAllUnitProperties(false)
+}
+
+func loadAllUnitProperties(applyTweaks bool) ([]types.UnitProperties, map[types.UnitRef]types.RawUnitDef) {
+	_, labGrid := LoadGridLayouts()
+
+	env := newLuaEnv()
+	defer env.Close()
+
+	defs, unitDefs, err := evaluateUnitDefs(env, applyTweaks)
+	if err != nil {
+		slog.Error("failed to glob", "error", err)
+		return nil, nil
+	}
+	moveDefs := loadMoveDefs(env)
+	reportMissing(env, "unit definitions")
+
+	unitProperties := make([]types.UnitProperties, 0)
+	rawUnitDefs := make(map[types.UnitRef]types.RawUnitDef)
+	add := func(ref string, file string, data *lua.LTable) {
+		up, err := unitPropertiesFromTable(data, ref)
+		if err != nil {
+			slog.Error("failed to parse unit properties", "ref", ref)
+			return
+		}
+		up.Source = sourceForFile(file, ref)
+		resolveMovement(&up.Movement, moveDefs)
 		unitProperties = append(unitProperties, *up)
-		// if _, ok := upByRef[ref]; ok {
-		// 	slog.Warn("overwriting existing ref", "ref", ref)
-		// }
-		// upByRef[ref] = *up
+		rawUnitDefs[ref] = types.RawUnitDef{
+			File: repoRelativePath(file),
+			Def:  paramsFromTable(data),
+		}
+	}
+	files := make(map[string]string)
+	for _, def := range defs {
+		if def.err != nil {
+			continue
+		}
+		files[def.ref] = def.file
+		data, ok := unitDefs.RawGetString(def.ref).(*lua.LTable)
+		if !ok {
+			slog.Error("unit definition removed or not a table", "ref", def.ref)
+			continue
+		}
+		add(def.ref, def.file, data)
+	}
+
+	if !includePvE {
+		return fixTechLevel(unitProperties, labGrid), rawUnitDefs
 	}
 
-	return fixTechLevel(unitProperties, labGrid)
+	// PvE units only exist in PvE games, evaluate the definitions again as one
+	// and keep the PvE units the stock evaluation doesn't have. Stock units
+	// keep their values from the regular game.
+	pveEnv := newPvELuaEnv()
+	defer pveEnv.Close()
+	_, pveUnitDefs, err := evaluateUnitDefs(pveEnv, applyTweaks)
+	if err != nil {
+		slog.Error("failed to glob", "error", err)
+		return fixTechLevel(unitProperties, labGrid), rawUnitDefs
+	}
+	reportMissing(pveEnv, "PvE unit definitions")
+	pveRefs := make([]string, 0)
+	pveUnitDefs.ForEach(func(k lua.LValue, v lua.LValue) {
+		if _, ok := v.(*lua.LTable); ok && k.Type() == lua.LTString {
+			pveRefs = append(pveRefs, k.String())
+		}
+	})
+	sort.Strings(pveRefs)
+	for _, ref := range pveRefs {
+		if _, ok := rawUnitDefs[ref]; ok {
+			continue
+		}
+		// Generated variants are defined by the file of the unit they're based on
+		file, ok := files[ref]
+		if !ok {
+			file = files[strings.TrimSuffix(ref, "_scav")]
+		}
+		if sourceForFile(file, ref) == types.SourceStock {
+			continue
+		}
+		add(ref, file, pveUnitDefs.RawGetString(ref).(*lua.LTable))
+	}
+
+	return fixTechLevel(unitProperties, labGrid), rawUnitDefs
+}
+
+// repoRelativePath returns file relative to the game repo with forward
+// slashes, as used in VFS calls.
+func repoRelativePath(file string) string {
+	rel, err := filepath.Rel(os.Getenv("GAME_REPO"), file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// sourceForFile tags PvE units by the directory they are defined in. Scavenger
+// variants of regular units use a "_scav" suffix.
+func sourceForFile(file string, ref string) types.UnitSource {
+	path := strings.ToLower(filepath.ToSlash(file))
+	switch {
+	case strings.Contains(path, "/scavengers/") || strings.HasSuffix(ref, "_scav"):
+		return types.SourceScavengers
+	case strings.Contains(path, "/raptors/") || strings.HasPrefix(ref, "raptor"):
+		return types.SourceRaptors
+	}
+	return types.SourceStock
 }
 
 // func findUnitPropertiesFile(ref types.UnitRef) (string, error) {
@@ -191,31 +363,53 @@ func LoadAllUnitProperties() []types.UnitProperties {
 // 	return parseUnitProperties(string(fileContents), ref, nil)
 // }
 
-func parseUnitProperties(luaContent string, ref string) (*types.UnitProperties, error) {
-	L := lua.NewState()
-	defer L.Close()
-
-	if err := L.DoString(luaContent); err != nil {
+// evaluateUnitFile runs a unit file in L and returns the table for ref. Name
+// is the chunk name Lua errors are reported with.
+func evaluateUnitFile(L *lua.LState, luaContent string, name string, ref string) (*lua.LTable, error) {
+	top := L.GetTop()
+	defer L.SetTop(top)
+	fn, err := L.Load(strings.NewReader(luaContent), name)
+	if err != nil {
+		return nil, err
+	}
+	L.Push(fn)
+	if err := L.PCall(0, lua.MultRet, nil); err != nil {
 		return nil, err
 	}
 
 	lv := L.Get(-1)
 	t, ok := lv.(*lua.LTable)
 	if !ok {
-		return nil, fmt.Errorf("%s: file does not contain a lua table", ref)
+		return nil, fmt.Errorf("file does not contain a lua table")
 	}
 	lData := t.RawGetString(ref)
 	data, ok := lData.(*lua.LTable)
 	if !ok {
-		return nil, fmt.Errorf("%s: file does not contain a ref key with a lua table", ref)
+		return nil, fmt.Errorf("file does not contain a ref key with a lua table")
 	}
+	return data, nil
+}
+
+func parseUnitProperties(luaContent string, ref string) (*types.UnitProperties, error) {
+	env := newLuaEnv()
+	defer env.Close()
 
+	data, err := evaluateUnitFile(env.L, luaContent, ref+".lua", ref)
+	if err != nil {
+		return nil, err
+	}
+	return unitPropertiesFromTable(data, ref)
+}
+
+func unitPropertiesFromTable(data *lua.LTable, ref string) (*types.UnitProperties, error) {
+	return readUnitProperties(&LuaTableParser{data: data}, ref)
+}
+
+func readUnitProperties(p *LuaTableParser, ref string) (*types.UnitProperties, error) {
 	properties := types.UnitProperties{
 		Ref: ref,
 	}
 
-	p := LuaTableParser{data}
-
 	// Simple stats assignments
 
 	properties.MetalCost = IgnoreError("metalcost", p.Int64)
@@ -232,13 +426,27 @@ func parseUnitProperties(luaContent string, ref string) (*types.UnitProperties,
 	properties.Health = IgnoreError("health", p.Int64)
 	properties.SightDistance = int64(IgnoreError("sightdistance", p.Float64))
 	properties.Speed = IgnoreError("speed", p.Float64)
+	properties.Movement = types.Movement{
+		MovementClass:  strings.ToUpper(IgnoreError("movementclass", p.String)),
+		MaxAcc:         IgnoreError("maxacc", p.Float64),
+		MaxDec:         IgnoreError("maxdec", p.Float64),
+		TurnRate:       IgnoreError("turnrate", p.Float64),
+		MaxSlope:       IgnoreError("maxslope", p.Float64),
+		MaxWaterDepth:  IgnoreError("maxwaterdepth", p.Float64),
+		MinWaterDepth:  IgnoreError("minwaterdepth", p.Float64),
+		CanFly:         IgnoreError("canfly", p.Bool),
+		CruiseAltitude: IgnoreError("cruisealtitude", p.Float64),
+		CanHover:       IgnoreError("canhover", p.Bool),
+	}
 	properties.Buildpower = IgnoreError("workertime", p.Int64)
 	properties.RadarDistance = IgnoreError("radardistance", p.Int64)
 	properties.JammerDistance = IgnoreError("radardistancejam", p.Int64)
 	properties.SonarDistance = IgnoreError("sonardistance", p.Int64)
+	properties.ExplodeAs = strings.ToLower(IgnoreError("explodeas", p.String))
+	properties.SelfDestructAs = strings.ToLower(IgnoreError("selfdestructas", p.String))
 
 	// Build option slice
-	bo := data.RawGetString("buildoptions")
+	bo := p.get("buildoptions")
 	var buildOptions []types.UnitRef
 	if bo.Type() == lua.LTTable {
 		buildOptions = make([]types.UnitRef, 0)
@@ -248,21 +456,70 @@ func parseUnitProperties(luaContent string, ref string) (*types.UnitProperties,
 	}
 
 	// Custom params
-	cp := data.RawGetString("customparams")
+	cp := p.get("customparams")
 	customParams := types.CustomParams{}
 	if cp.Type() == lua.LTTable {
 		customParams.TechLevel, _ = strconv.Atoi(cp.(*lua.LTable).RawGetString("techlevel").String())
 		customParams.UnitGroup = cp.(*lua.LTable).RawGetString("unitgroup").String()
+		customParams.Params = paramsFromTable(cp.(*lua.LTable))
+	}
+
+	// Corpses
+	if fd := IgnoreError("featuredefs", p.Table); fd != nil {
+		if dead := IgnoreError("dead", fd.Table); dead != nil {
+			properties.Wreck = parseFeatureDef(dead)
+		}
+		if heap := IgnoreError("heap", fd.Table); heap != nil {
+			properties.Heap = parseFeatureDef(heap)
+		}
 	}
 
 	properties.BuildOptions = buildOptions
+	// Factories build through their own command queue and can't reclaim
+	isFactory := properties.Speed == 0 && len(buildOptions) > 0
+	properties.Abilities = parseAbilities(p, properties.Buildpower != 0 && !isFactory)
 	properties.CustomParams = customParams
-	properties.Weapons = ParseWeapons(data)
-	properties.WeaponDefs = ParseWeaponDefs(data)
+	properties.Weapons = parseWeapons(p)
+	properties.WeaponDefs = parseWeaponDefs(p)
 
 	return &properties, nil
 }
 
+// parseAbilities reads the ability flags, reclaiming and repairing default to
+// enabled for builders like the engine does.
+func parseAbilities(p *LuaTableParser, isBuilder bool) types.Abilities {
+	abilities := types.Abilities{
+		CanCloak:          IgnoreError("cancloak", p.Flag),
+		CloakCost:         IgnoreError("cloakcost", p.Float64),
+		CloakCostMoving:   IgnoreError("cloakcostmoving", p.Float64),
+		Stealth:           IgnoreError("stealth", p.Flag),
+		SonarStealth:      IgnoreError("sonarstealth", p.Flag),
+		CanResurrect:      IgnoreError("canresurrect", p.Flag),
+		CanReclaim:        isBuilder,
+		CanCapture:        IgnoreError("cancapture", p.Flag),
+		CanRepair:         isBuilder,
+		OnOffable:         IgnoreError("onoffable", p.Flag),
+		TransportCapacity: IgnoreError("transportcapacity", p.Int64),
+		BuildDistance:     IgnoreError("builddistance", p.Float64),
+	}
+	if p.Has("canreclaim") {
+		abilities.CanReclaim = IgnoreError("canreclaim", p.Flag)
+	}
+	if p.Has("canrepair") {
+		abilities.CanRepair = IgnoreError("canrepair", p.Flag)
+	}
+	return abilities
+}
+
+func parseFeatureDef(p *LuaTableParser) types.FeatureDef {
+	return types.FeatureDef{
+		Metal:         IgnoreError("metal", p.Float64),
+		Energy:        IgnoreError("energy", p.Float64),
+		Damage:        IgnoreError("damage", p.Float64),
+		Resurrectable: IgnoreError("resurrectable", p.Flag),
+	}
+}
+
 func findUnitPropertiesByRef(unitProperties []types.UnitProperties, ref types.UnitRef) (*types.UnitProperties, bool) {
 	for _, up := range unitProperties {
 		if up.Ref == ref {
@@ -329,14 +586,17 @@ func fixTechLevel(unitProperties []types.UnitProperties, labGrid types.LabGrid)
 }
 
 func ParseWeapons(data *lua.LTable) []types.Weapon {
+	return parseWeapons(&LuaTableParser{data: data})
+}
+
+func parseWeapons(up *LuaTableParser) []types.Weapon {
 	weapons := make([]types.Weapon, 0)
-	w := data.RawGetString("weapons")
+	w := up.get("weapons")
 	if w.Type() != lua.LTTable {
 		return nil
 	}
 	w.(*lua.LTable).ForEach(func(k lua.LValue, v lua.LValue) {
-		vT := v.(*lua.LTable)
-		p := LuaTableParser{vT}
+		p := up.table(v.(*lua.LTable))
 
 		weapon := types.Weapon{
 			BadTargetCategory:   IgnoreError("badtargetcategory", p.ListString),
@@ -352,201 +612,224 @@ func ParseWeapons(data *lua.LTable) []types.Weapon {
 }
 
 func ParseWeaponDefs(data *lua.LTable) map[string]types.WeaponDef {
+	return parseWeaponDefs(&LuaTableParser{data: data})
+}
+
+func parseWeaponDefs(up *LuaTableParser) map[string]types.WeaponDef {
 	defs := make(map[string]types.WeaponDef, 0)
-	wd := data.RawGetString("weapondefs")
+	wd := up.get("weapondefs")
 	if wd.Type() != lua.LTTable {
 		return nil
 	}
 
 	wd.(*lua.LTable).ForEach(func(k lua.LValue, v lua.LValue) {
-		vT := v.(*lua.LTable)
-		p := LuaTableParser{vT}
-		def := types.WeaponDef{
-			Name:                     IgnoreError("name", p.String),
-			WeaponType:               IgnoreError("weapontype", p.String),
-			Id:                       IgnoreError("id", p.Int64),
-			CustomParams:             make(map[string]interface{}),
-			AvoidFriendly:            IgnoreError("avoidfriendly", p.Bool),
-			AvoidFeature:             IgnoreError("avoidfeature", p.Bool),
-			AvoidNeutral:             IgnoreError("avoidneutral", p.Bool),
-			AvoidGround:              IgnoreError("avoidground", p.Bool),
-			AvoidCloaked:             IgnoreError("avoidcloaked", p.Bool),
-			CollideEnemy:             IgnoreError("collideenemy", p.Bool),
-			CollideFriendly:          IgnoreError("collidefriendly", p.Bool),
-			CollideFeature:           IgnoreError("collidefeature", p.Bool),
-			CollideNeutral:           IgnoreError("collideneutral", p.Bool),
-			CollideFireBase:          IgnoreError("collidefirebase", p.Bool),
-			CollideNonTarget:         IgnoreError("collidenontarget", p.Bool),
-			CollideGround:            IgnoreError("collideground", p.Bool),
-			CollideCloaked:           IgnoreError("collidecloaked", p.Bool),
-			Damage:                   make(map[string]float64),
-			ExplosionSpeed:           IgnoreError("explosionspeed", p.Float64),
-			ImpactOnly:               IgnoreError("impactonly", p.Bool),
-			NoSelfDamage:             IgnoreError("noselfdamage", p.Bool),
-			NoExplode:                IgnoreError("noexplode", p.Bool),
-			Burnblow:                 IgnoreError("burnblow", p.Bool),
-			DamageAreaOfEffect:       IgnoreError("damageareaofeffect", p.Float64),
-			EdgeEffectiveness:        IgnoreError("edgeeffectiveness", p.Float64),
-			CollisionSize:            IgnoreError("collisionsize", p.Float64),
-			WeaponVelocity:           IgnoreError("weaponvelocity", p.Float64),
-			StartVelocity:            IgnoreError("startvelocity", p.Float64),
-			Weaponacceleration:       IgnoreError("weaponacceleration", p.Float64),
-			ReloadTime:               IgnoreError("reloadtime", p.Float64),
-			BurstRate:                IgnoreError("burstrate", p.Float64),
-			Burst:                    IgnoreError("burst", p.Int64),
-			Projectiles:              IgnoreError("projectiles", p.Int64),
-			WaterBounce:              IgnoreError("waterbounce", p.Bool),
-			GroundBounce:             IgnoreError("groundbounce", p.Bool),
-			BounceSlip:               IgnoreError("bounceslip", p.Float64),
-			BounceRebound:            IgnoreError("bouncerebound", p.Float64),
-			NumBounce:                IgnoreError("numbounce", p.Int64),
-			ImpulseFactor:            IgnoreError("impulsefactor", p.Float64),
-			ImpulseBoost:             IgnoreError("impulseboost", p.Float64),
-			CraterMult:               IgnoreError("cratermult", p.Float64),
-			CraterBoost:              IgnoreError("craterboost", p.Float64),
-			CraterAreaOfEffect:       IgnoreError("craterareaofeffect", p.Float64),
-			Waterweapon:              IgnoreError("waterweapon", p.Bool),
-			Submissile:               IgnoreError("submissile", p.Bool),
-			FireSubmersed:            IgnoreError("firesubmersed", p.Bool),
-			Commandfire:              IgnoreError("commandfire", p.Bool),
-			Range:                    IgnoreError("range", p.Float64),
-			Heightmod:                IgnoreError("heightmod", p.Float64),
-			TargetBorder:             IgnoreError("targetborder", p.Float64),
-			CylinderTargeting:        IgnoreError("cylindertargeting", p.Float64),
-			Turret:                   IgnoreError("turret", p.Bool),
-			FixedLauncher:            IgnoreError("fixedlauncher", p.Bool),
-			Tolerance:                IgnoreError("tolerance", p.Float64),
-			Firetolerance:            IgnoreError("firetolerance", p.Float64),
-			HighTrajectory:           IgnoreError("hightrajectory", p.Int64),
-			TrajectoryHeight:         IgnoreError("trajectoryheight", p.Float64),
-			Tracks:                   IgnoreError("tracks", p.Bool),
-			Wobble:                   IgnoreError("wobble", p.Float64),
-			Dance:                    IgnoreError("dance", p.Float64),
-			GravityAffected:          IgnoreError("gravityaffected", p.Bool),
-			MyGravity:                IgnoreError("mygravity", p.Float64),
-			CanAttackGround:          IgnoreError("canattackground", p.Bool),
-			WeaponTimer:              IgnoreError("weapontimer", p.Float64),
-			Flighttime:               IgnoreError("flighttime", p.Float64),
-			Turnrate:                 IgnoreError("turnrate", p.Float64),
-			HeightBoostFactor:        IgnoreError("heightboostfactor", p.Float64),
-			ProximityPriority:        IgnoreError("proximitypriority", p.Float64),
-			AllowNonBlockingAim:      IgnoreError("allownonblockingaim", p.Bool),
-			Accuracy:                 IgnoreError("accuracy", p.Float64),
-			SprayAngle:               IgnoreError("sprayangle", p.Float64),
-			MovingAccuracy:           IgnoreError("movingaccuracy", p.Float64),
-			TargetMoveError:          IgnoreError("targetmoveerror", p.Float64),
-			LeadLimit:                IgnoreError("leadlimit", p.Float64),
-			LeadBonus:                IgnoreError("leadbonus", p.Float64),
-			PredictBoost:             IgnoreError("predictboost", p.Float64),
-			OwnerExpAccWeight:        IgnoreError("ownerexpaccweight", p.Float64),
-			MinIntensity:             IgnoreError("minintensity", p.Float64),
-			Duration:                 IgnoreError("duration", p.Float64),
-			Beamtime:                 IgnoreError("beamtime", p.Float64),
-			Beamburst:                IgnoreError("beamburst", p.Bool),
-			BeamTTL:                  IgnoreError("beamttl", p.Int64),
-			SweepFire:                IgnoreError("sweepfire", p.Bool),
-			LargeBeamLaser:           IgnoreError("largebeamlaser", p.Bool),
-			SizeGrowth:               IgnoreError("sizegrowth", p.Float64),
-			FlameGfxTime:             IgnoreError("flamegfxtime", p.Float64),
-			MetalPerShot:             IgnoreError("metalpershot", p.Float64),
-			EnergyPerShot:            IgnoreError("energypershot", p.Float64),
-			FireStarter:              IgnoreError("firestarter", p.Float64),
-			Paralyzer:                IgnoreError("paralyzer", p.Bool),
-			ParalyzeTime:             IgnoreError("paralyzetime", p.Int64),
-			Stockpile:                IgnoreError("stockpile", p.Bool),
-			StockpileTime:            IgnoreError("stockpiletime", p.Float64),
-			Targetable:               IgnoreError("targetable", p.Int64),
-			Interceptor:              IgnoreError("interceptor", p.Int64),
-			InterceptedByShieldType:  IgnoreError("interceptedbyshieldtype", p.Int64),
-			Coverage:                 IgnoreError("coverage", p.Float64),
-			InterceptSolo:            IgnoreError("interceptsolo", p.Bool),
-			DynDamageInverted:        IgnoreError("dyndamageinverted", p.Bool),
-			DynDamageExp:             IgnoreError("dyndamageexp", p.Float64),
-			DynDamageMin:             IgnoreError("dyndamagemin", p.Float64),
-			DynDamageRange:           IgnoreError("dyndamagerange", p.Float64),
-			Shield:                   types.Shield{},
-			RechargeDelay:            IgnoreError("rechargedelay", p.Float64),
-			Model:                    IgnoreError("model", p.String),
-			Size:                     IgnoreError("size", p.Float64),
-			ScarGlowColorMap:         IgnoreError("scarglowcolormap", p.String),
-			ScarIndices:              types.ScarIndices{},
-			ExplosionScar:            IgnoreError("explosionscar", p.Bool),
-			ScarDiameter:             IgnoreError("scardiameter", p.Float64),
-			ScarAlpha:                IgnoreError("scaralpha", p.Float64),
-			ScarGlow:                 IgnoreError("scarglow", p.Float64),
-			ScarTtl:                  IgnoreError("scarttl", p.Float64),
-			ScarGlowTtl:              IgnoreError("scarglowttl", p.Float64),
-			ScarDotElimination:       IgnoreError("scardotelimination", p.Float64),
-			ScarProjVector:           [4]float64{},
-			ScarColorTint:            [4]float64{},
-			AlwaysVisible:            IgnoreError("alwaysvisible", p.Bool),
-			CameraShake:              IgnoreError("camerashake", p.Float64),
-			SmokeTrail:               IgnoreError("smoketrail", p.Bool),
-			SmokeTrailCastShadow:     IgnoreError("smoketrailcastshadow", p.Bool),
-			SmokePeriod:              IgnoreError("smokeperiod", p.Int64),
-			SmokeTime:                IgnoreError("smoketime", p.Int64),
-			SmokeSize:                IgnoreError("smokesize", p.Float64),
-			SmokeColor:               IgnoreError("smokecolor", p.Float64),
-			CastShadow:               IgnoreError("castshadow", p.Bool),
-			SizeDecay:                IgnoreError("sizedecay", p.Float64),
-			AlphaDecay:               IgnoreError("alphadecay", p.Float64),
-			Separation:               IgnoreError("separation", p.Float64),
-			NoGap:                    IgnoreError("nogap", p.Bool),
-			Stages:                   IgnoreError("stages", p.Int64),
-			LodDistance:              IgnoreError("loddistance", p.Int64),
-			Thickness:                IgnoreError("thickness", p.Float64),
-			CoreThickness:            IgnoreError("corethickness", p.Float64),
-			LaserFlareSize:           IgnoreError("laserflaresize", p.Float64),
-			TileLength:               IgnoreError("tilelength", p.Float64),
-			ScrollSpeed:              IgnoreError("scrollspeed", p.Float64),
-			PulseSpeed:               IgnoreError("pulsespeed", p.Float64),
-			BeamDecay:                IgnoreError("beamdecay", p.Float64),
-			FalloffRate:              IgnoreError("falloffrate", p.Float64),
-			Hardstop:                 IgnoreError("hardstop", p.Bool),
-			RgbColor:                 [3]float64{},
-			RgbColor2:                [3]float64{},
-			Intensity:                IgnoreError("intensity", p.Float64),
-			Colormap:                 IgnoreError("colormap", p.String),
-			CegTag:                   IgnoreError("cegtag", p.String),
-			ExplosionGenerator:       IgnoreError("explosiongenerator", p.String),
-			BounceExplosionGenerator: IgnoreError("bounceexplosiongenerator", p.String),
-			SoundTrigger:             IgnoreError("soundtrigger", p.Bool),
-			SoundStart:               IgnoreError("soundstart", p.String),
-			SoundHitDry:              IgnoreError("soundhitdry", p.String),
-			SoundHitWet:              IgnoreError("soundhitwet", p.String),
-			SoundStartVolume:         IgnoreError("soundstartvolume", p.Float64),
-			SoundHitDryVolume:        IgnoreError("soundhitdryvolume", p.Float64),
-			SoundHitWetVolume:        IgnoreError("soundhitwetvolume", p.Float64),
+		vT, ok := v.(*lua.LTable)
+		if !ok {
+			return
 		}
+		defs[k.String()] = parseWeaponDef(up.table(vT))
+	})
+	return defs
+}
 
-		customParams := IgnoreError("customparams", p.Table)
-		if customParams != nil {
-			customParams.data.ForEach(func(k lua.LValue, v lua.LValue) {
-				if v.Type() != lua.LTNumber && v.Type() != lua.LTString {
-					return
-				}
-				customParamValue, err := strconv.ParseFloat(v.String(), 64)
-				if err != nil {
-					return
-				}
-				def.CustomParams[k.String()] = customParamValue
-			})
-		}
+func parseWeaponDef(p *LuaTableParser) types.WeaponDef {
+	def := types.WeaponDef{
+		Name:                     IgnoreError("name", p.String),
+		WeaponType:               IgnoreError("weapontype", p.String),
+		Id:                       IgnoreError("id", p.Int64),
+		CustomParams:             make(types.Params),
+		AvoidFriendly:            IgnoreError("avoidfriendly", p.Bool),
+		AvoidFeature:             IgnoreError("avoidfeature", p.Bool),
+		AvoidNeutral:             IgnoreError("avoidneutral", p.Bool),
+		AvoidGround:              IgnoreError("avoidground", p.Bool),
+		AvoidCloaked:             IgnoreError("avoidcloaked", p.Bool),
+		CollideEnemy:             IgnoreError("collideenemy", p.Bool),
+		CollideFriendly:          IgnoreError("collidefriendly", p.Bool),
+		CollideFeature:           IgnoreError("collidefeature", p.Bool),
+		CollideNeutral:           IgnoreError("collideneutral", p.Bool),
+		CollideFireBase:          IgnoreError("collidefirebase", p.Bool),
+		CollideNonTarget:         IgnoreError("collidenontarget", p.Bool),
+		CollideGround:            IgnoreError("collideground", p.Bool),
+		CollideCloaked:           IgnoreError("collidecloaked", p.Bool),
+		Damage:                   make(map[string]float64),
+		ExplosionSpeed:           IgnoreError("explosionspeed", p.Float64),
+		ImpactOnly:               IgnoreError("impactonly", p.Bool),
+		NoSelfDamage:             IgnoreError("noselfdamage", p.Bool),
+		NoExplode:                IgnoreError("noexplode", p.Bool),
+		Burnblow:                 IgnoreError("burnblow", p.Bool),
+		DamageAreaOfEffect:       IgnoreError("damageareaofeffect", p.Float64),
+		EdgeEffectiveness:        IgnoreError("edgeeffectiveness", p.Float64),
+		CollisionSize:            IgnoreError("collisionsize", p.Float64),
+		WeaponVelocity:           IgnoreError("weaponvelocity", p.Float64),
+		StartVelocity:            IgnoreError("startvelocity", p.Float64),
+		Weaponacceleration:       IgnoreError("weaponacceleration", p.Float64),
+		ReloadTime:               IgnoreError("reloadtime", p.Float64),
+		BurstRate:                IgnoreError("burstrate", p.Float64),
+		Burst:                    IgnoreError("burst", p.Int64),
+		Projectiles:              IgnoreError("projectiles", p.Int64),
+		WaterBounce:              IgnoreError("waterbounce", p.Bool),
+		GroundBounce:             IgnoreError("groundbounce", p.Bool),
+		BounceSlip:               IgnoreError("bounceslip", p.Float64),
+		BounceRebound:            IgnoreError("bouncerebound", p.Float64),
+		NumBounce:                IgnoreError("numbounce", p.Int64),
+		ImpulseFactor:            IgnoreError("impulsefactor", p.Float64),
+		ImpulseBoost:             IgnoreError("impulseboost", p.Float64),
+		CraterMult:               IgnoreError("cratermult", p.Float64),
+		CraterBoost:              IgnoreError("craterboost", p.Float64),
+		CraterAreaOfEffect:       IgnoreError("craterareaofeffect", p.Float64),
+		Waterweapon:              IgnoreError("waterweapon", p.Bool),
+		Submissile:               IgnoreError("submissile", p.Bool),
+		FireSubmersed:            IgnoreError("firesubmersed", p.Bool),
+		Commandfire:              IgnoreError("commandfire", p.Bool),
+		Range:                    IgnoreError("range", p.Float64),
+		Heightmod:                IgnoreError("heightmod", p.Float64),
+		TargetBorder:             IgnoreError("targetborder", p.Float64),
+		CylinderTargeting:        IgnoreError("cylindertargeting", p.Float64),
+		Turret:                   IgnoreError("turret", p.Bool),
+		FixedLauncher:            IgnoreError("fixedlauncher", p.Bool),
+		Tolerance:                IgnoreError("tolerance", p.Float64),
+		Firetolerance:            IgnoreError("firetolerance", p.Float64),
+		HighTrajectory:           IgnoreError("hightrajectory", p.Int64),
+		TrajectoryHeight:         IgnoreError("trajectoryheight", p.Float64),
+		Tracks:                   IgnoreError("tracks", p.Bool),
+		Wobble:                   IgnoreError("wobble", p.Float64),
+		Dance:                    IgnoreError("dance", p.Float64),
+		GravityAffected:          IgnoreError("gravityaffected", p.Bool),
+		MyGravity:                IgnoreError("mygravity", p.Float64),
+		CanAttackGround:          IgnoreError("canattackground", p.Bool),
+		WeaponTimer:              IgnoreError("weapontimer", p.Float64),
+		Flighttime:               IgnoreError("flighttime", p.Float64),
+		Turnrate:                 IgnoreError("turnrate", p.Float64),
+		HeightBoostFactor:        IgnoreError("heightboostfactor", p.Float64),
+		ProximityPriority:        IgnoreError("proximitypriority", p.Float64),
+		AllowNonBlockingAim:      IgnoreError("allownonblockingaim", p.Bool),
+		Accuracy:                 IgnoreError("accuracy", p.Float64),
+		SprayAngle:               IgnoreError("sprayangle", p.Float64),
+		MovingAccuracy:           IgnoreError("movingaccuracy", p.Float64),
+		TargetMoveError:          IgnoreError("targetmoveerror", p.Float64),
+		LeadLimit:                IgnoreError("leadlimit", p.Float64),
+		LeadBonus:                IgnoreError("leadbonus", p.Float64),
+		PredictBoost:             IgnoreError("predictboost", p.Float64),
+		OwnerExpAccWeight:        IgnoreError("ownerexpaccweight", p.Float64),
+		MinIntensity:             IgnoreError("minintensity", p.Float64),
+		Duration:                 IgnoreError("duration", p.Float64),
+		Beamtime:                 IgnoreError("beamtime", p.Float64),
+		Beamburst:                IgnoreError("beamburst", p.Bool),
+		BeamTTL:                  IgnoreError("beamttl", p.Int64),
+		SweepFire:                IgnoreError("sweepfire", p.Bool),
+		LargeBeamLaser:           IgnoreError("largebeamlaser", p.Bool),
+		SizeGrowth:               IgnoreError("sizegrowth", p.Float64),
+		FlameGfxTime:             IgnoreError("flamegfxtime", p.Float64),
+		MetalPerShot:             IgnoreError("metalpershot", p.Float64),
+		EnergyPerShot:            IgnoreError("energypershot", p.Float64),
+		FireStarter:              IgnoreError("firestarter", p.Float64),
+		Paralyzer:                IgnoreError("paralyzer", p.Bool),
+		ParalyzeTime:             IgnoreError("paralyzetime", p.Int64),
+		Stockpile:                IgnoreError("stockpile", p.Bool),
+		StockpileTime:            IgnoreError("stockpiletime", p.Float64),
+		Targetable:               IgnoreError("targetable", p.Int64),
+		Interceptor:              IgnoreError("interceptor", p.Int64),
+		InterceptedByShieldType:  IgnoreError("interceptedbyshieldtype", p.Int64),
+		Coverage:                 IgnoreError("coverage", p.Float64),
+		InterceptSolo:            IgnoreError("interceptsolo", p.Bool),
+		DynDamageInverted:        IgnoreError("dyndamageinverted", p.Bool),
+		DynDamageExp:             IgnoreError("dyndamageexp", p.Float64),
+		DynDamageMin:             IgnoreError("dyndamagemin", p.Float64),
+		DynDamageRange:           IgnoreError("dyndamagerange", p.Float64),
+		Shield:                   types.Shield{},
+		RechargeDelay:            IgnoreError("rechargedelay", p.Float64),
+		Model:                    IgnoreError("model", p.String),
+		Size:                     IgnoreError("size", p.Float64),
+		ScarGlowColorMap:         IgnoreError("scarglowcolormap", p.String),
+		ScarIndices:              types.ScarIndices{},
+		ExplosionScar:            IgnoreError("explosionscar", p.Bool),
+		ScarDiameter:             IgnoreError("scardiameter", p.Float64),
+		ScarAlpha:                IgnoreError("scaralpha", p.Float64),
+		ScarGlow:                 IgnoreError("scarglow", p.Float64),
+		ScarTtl:                  IgnoreError("scarttl", p.Float64),
+		ScarGlowTtl:              IgnoreError("scarglowttl", p.Float64),
+		ScarDotElimination:       IgnoreError("scardotelimination", p.Float64),
+		ScarProjVector:           [4]float64{},
+		ScarColorTint:            [4]float64{},
+		AlwaysVisible:            IgnoreError("alwaysvisible", p.Bool),
+		CameraShake:              IgnoreError("camerashake", p.Float64),
+		SmokeTrail:               IgnoreError("smoketrail", p.Bool),
+		SmokeTrailCastShadow:     IgnoreError("smoketrailcastshadow", p.Bool),
+		SmokePeriod:              IgnoreError("smokeperiod", p.Int64),
+		SmokeTime:                IgnoreError("smoketime", p.Int64),
+		SmokeSize:                IgnoreError("smokesize", p.Float64),
+		SmokeColor:               IgnoreError("smokecolor", p.Float64),
+		CastShadow:               IgnoreError("castshadow", p.Bool),
+		SizeDecay:                IgnoreError("sizedecay", p.Float64),
+		AlphaDecay:               IgnoreError("alphadecay", p.Float64),
+		Separation:               IgnoreError("separation", p.Float64),
+		NoGap:                    IgnoreError("nogap", p.Bool),
+		Stages:                   IgnoreError("stages", p.Int64),
+		LodDistance:              IgnoreError("loddistance", p.Int64),
+		Thickness:                IgnoreError("thickness", p.Float64),
+		CoreThickness:            IgnoreError("corethickness", p.Float64),
+		LaserFlareSize:           IgnoreError("laserflaresize", p.Float64),
+		TileLength:               IgnoreError("tilelength", p.Float64),
+		ScrollSpeed:              IgnoreError("scrollspeed", p.Float64),
+		PulseSpeed:               IgnoreError("pulsespeed", p.Float64),
+		BeamDecay:                IgnoreError("beamdecay", p.Float64),
+		FalloffRate:              IgnoreError("falloffrate", p.Float64),
+		Hardstop:                 IgnoreError("hardstop", p.Bool),
+		RgbColor:                 [3]float64{},
+		RgbColor2:                [3]float64{},
+		Intensity:                IgnoreError("intensity", p.Float64),
+		Colormap:                 IgnoreError("colormap", p.String),
+		CegTag:                   IgnoreError("cegtag", p.String),
+		ExplosionGenerator:       IgnoreError("explosiongenerator", p.String),
+		BounceExplosionGenerator: IgnoreError("bounceexplosiongenerator", p.String),
+		SoundTrigger:             IgnoreError("soundtrigger", p.Bool),
+		SoundStart:               IgnoreError("soundstart", p.String),
+		SoundHitDry:              IgnoreError("soundhitdry", p.String),
+		SoundHitWet:              IgnoreError("soundhitwet", p.String),
+		SoundStartVolume:         IgnoreError("soundstartvolume", p.Float64),
+		SoundHitDryVolume:        IgnoreError("soundhitdryvolume", p.Float64),
+		SoundHitWetVolume:        IgnoreError("soundhitwetvolume", p.Float64),
+	}
 
-		damage := IgnoreError("damage", p.Table)
-		damage.data.ForEach(func(k lua.LValue, v lua.LValue) {
-			if v.Type() != lua.LTNumber && v.Type() != lua.LTString {
-				return
-			}
-			damageValue, err := strconv.ParseFloat(v.String(), 64)
-			if err != nil {
-				return
-			}
-			def.Damage[k.String()] = damageValue
-		})
-		defs[k.String()] = def
+	if customParams := IgnoreError("customparams", p.Params); customParams != nil {
+		def.CustomParams = customParams
+	}
+
+	// Weapon files use the areaofeffect alias
+	if def.DamageAreaOfEffect == 0 {
+		def.DamageAreaOfEffect = IgnoreError("areaofeffect", p.Float64)
+	}
+
+	damage := IgnoreError("damage", p.Table)
+	if damage == nil {
+		return def
+	}
+	damage.data.ForEach(func(k lua.LValue, v lua.LValue) {
+		if v.Type() != lua.LTNumber && v.Type() != lua.LTString {
+			return
+		}
+		damageValue, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil {
+			return
+		}
+		def.Damage[k.String()] = damageValue
 	})
-	return defs
+	return def
+}
+
+// LoadAllTranslations loads the unit translations of every language in the
+// game repo, keyed by language code.
+func LoadAllTranslations() map[string]types.Translations {
+	files, err := filepath.Glob(fmt.Sprintf("%s/language/*/units.json", os.Getenv("GAME_REPO")))
+	if err != nil {
+		panic(err)
+	}
+	translations := make(map[string]types.Translations)
+	for _, f := range files {
+		lang := filepath.Base(filepath.Dir(f))
+		translations[lang] = LoadTranslations(lang)
+	}
+	return translations
 }
 
 func LoadTranslations(lang string) (t types.Translations) {
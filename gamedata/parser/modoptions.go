@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+var modOptions = types.ModOptions{}
+
+// SetModOptions sets the modoptions returned by Spring.GetModOptions while
+// evaluating unit definitions and grid layouts.
+func SetModOptions(mo types.ModOptions) {
+	modOptions = mo
+}
+
+func GetModOptions() types.ModOptions {
+	return modOptions
+}
+
+// LoadModOptionsFile reads modoptions from a JSON object. Values are stored as
+// text, numbers keep their JSON notation, Spring.GetModOptions converts
+// numbers and booleans back when scripts read them.
+func LoadModOptionsFile(path string) (types.ModOptions, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw := make(map[string]interface{})
+	d := json.NewDecoder(bytes.NewReader(content))
+	d.UseNumber()
+	if err := d.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	mo := make(types.ModOptions)
+	for k, v := range raw {
+		mo[strings.ToLower(k)] = fmt.Sprint(v)
+	}
+	return mo, nil
+}
+
+// ParseModOption parses a single key=value modoption.
+func ParseModOption(s string) (key string, value string, err error) {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.ToLower(strings.TrimSpace(key))
+	if !ok || key == "" {
+		err = fmt.Errorf("invalid modoption %q, expected key=value", s)
+		return
+	}
+	value = strings.TrimSpace(value)
+	return
+}
+
+// luaModOptions returns the modoptions passed to Lua. Tweaks are applied by
+// ApplyTweaks, hiding them keeps the game's post-processing scripts from
+// applying them a second time.
+func luaModOptions() map[string]string {
+	mo := make(map[string]string)
+	for k, v := range modOptions {
+		if strings.HasPrefix(k, "tweakdefs") || strings.HasPrefix(k, "tweakunits") {
+			continue
+		}
+		mo[k] = v
+	}
+	return mo
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+func TestParseModOption(t *testing.T) {
+	tests := []struct {
+		in        string
+		wantKey   string
+		wantValue string
+		wantErr   bool
+	}{
+		{"experimentallegionfaction=1", "experimentallegionfaction", "1", false},
+		{"  ScavDifficulty = hard ", "scavdifficulty", "hard", false},
+		{"tweakdefs=e2FybXB3PXt9fQ==", "tweakdefs", "e2FybXB3PXt9fQ==", false},
+		{"key=", "key", "", false},
+		{"key=a=b", "key", "a=b", false},
+		{"", "", "", true},
+		{"   ", "", "", true},
+		{"=1", "", "", true},
+		{" =1", "", "", true},
+		{"noequals", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			key, value, err := ParseModOption(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseModOption(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if key != tt.wantKey || value != tt.wantValue {
+				t.Errorf("ParseModOption(%q) = %q, %q, want %q, %q", tt.in, key, value, tt.wantKey, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestLoadModOptionsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "modoptions.json")
+	content := `{"ExperimentalLegionFaction": true, "multiplier_buildpower": 1.50, "maxunits": 2000, "map": "Supreme Isthmus"}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadModOptionsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := types.ModOptions{
+		"experimentallegionfaction": "true",
+		"multiplier_buildpower":     "1.50",
+		"maxunits":                  "2000",
+		"map":                       "Supreme Isthmus",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadModOptionsFile() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/wezzle/bar-unit-info/gamedata/luaenv"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const moveDefsFile = "gamedata/movedefs.lua"
+
+// amphibiousWaterDepth is the water depth from which a unit counts as
+// amphibious, regular bots and tanks are limited to shallow water.
+const amphibiousWaterDepth = 255
+
+// loadMoveDefs evaluates the game's movedefs.lua and returns the movement
+// classes by uppercase name. The file returns a list of definitions with a
+// name key.
+func loadMoveDefs(env *luaenv.Env) map[string]types.MoveDef {
+	moveDefs := make(map[string]types.MoveDef)
+	if !env.FileExists(moveDefsFile) {
+		slog.Info("skipping movement classes, file not found", "file", moveDefsFile)
+		return moveDefs
+	}
+
+	top := env.L.GetTop()
+	defer env.L.SetTop(top)
+	if err := env.DoFile(moveDefsFile); err != nil {
+		slog.Error("failed to evaluate movement classes", "file", moveDefsFile, "error", err)
+		return moveDefs
+	}
+	defs, ok := env.L.Get(-1).(*lua.LTable)
+	if !ok {
+		slog.Error("movement classes file does not contain a lua table", "file", moveDefsFile)
+		return moveDefs
+	}
+
+	defs.ForEach(func(k lua.LValue, v lua.LValue) {
+		t, ok := v.(*lua.LTable)
+		if !ok {
+			return
+		}
+		luaenv.LowerKeys(t)
+		p := LuaTableParser{data: t}
+		name := IgnoreError("name", p.String)
+		if name == "" {
+			// Older versions key the definitions by name
+			name = k.String()
+		}
+		md := types.MoveDef{
+			Name:          strings.ToUpper(name),
+			SpeedModClass: IgnoreError("speedmodclass", p.Int64),
+			CrushStrength: IgnoreError("crushstrength", p.Float64),
+			MaxSlope:      IgnoreError("maxslope", p.Float64),
+			MaxWaterDepth: IgnoreError("maxwaterdepth", p.Float64),
+			MinWaterDepth: IgnoreError("minwaterdepth", p.Float64),
+			Submarine:     IgnoreError("submarine", p.Flag),
+		}
+		moveDefs[md.Name] = md
+	})
+	return moveDefs
+}
+
+// resolveMovement fills in the movement class and the limits a unit inherits
+// from it.
+func resolveMovement(m *types.Movement, moveDefs map[string]types.MoveDef) {
+	if md, ok := moveDefs[m.MovementClass]; ok {
+		m.MoveDef = md
+		if m.MaxSlope == 0 {
+			m.MaxSlope = md.MaxSlope
+		}
+		if m.MaxWaterDepth == 0 {
+			m.MaxWaterDepth = md.MaxWaterDepth
+		}
+		if m.MinWaterDepth == 0 {
+			m.MinWaterDepth = md.MinWaterDepth
+		}
+	} else if m.MovementClass != "" {
+		slog.Warn("unknown movement class", "class", m.MovementClass)
+	}
+	m.Amphibious = !m.CanFly && m.MoveDef.SpeedModClass < 2 && m.MaxWaterDepth >= amphibiousWaterDepth
+}
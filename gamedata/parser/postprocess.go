@@ -0,0 +1,27 @@
+package parser
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/wezzle/bar-unit-info/gamedata/luaenv"
+)
+
+const unitDefsPostFile = "gamedata/unitdefs_post.lua"
+
+// ApplyPostProcessing runs the game's unitdefs_post.lua, which passes every unit
+// definition through alldefs_post.lua, over the UnitDefs global. Repos without
+// the gamedata directory are left untouched.
+func ApplyPostProcessing(env *luaenv.Env) error {
+	if !env.FileExists(unitDefsPostFile) {
+		slog.Info("skipping unit def post-processing, file not found", "file", unitDefsPostFile)
+		return nil
+	}
+
+	top := env.L.GetTop()
+	defer env.L.SetTop(top)
+	if err := env.DoFile(unitDefsPostFile); err != nil {
+		return fmt.Errorf("%s: %w", unitDefsPostFile, err)
+	}
+	return nil
+}
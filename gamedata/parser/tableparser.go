@@ -5,17 +5,66 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/wezzle/bar-unit-info/gamedata/types"
 	lua "github.com/yuin/gopher-lua"
 )
 
+// recorder records the keys read from each table and the keys holding a value
+// of the wrong type, for finding unknown keys and linting.
+type recorder struct {
+	reads      map[*lua.LTable]map[string]bool
+	typeErrors map[*lua.LTable]map[string]error
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		reads:      make(map[*lua.LTable]map[string]bool),
+		typeErrors: make(map[*lua.LTable]map[string]error),
+	}
+}
+
 type LuaTableParser struct {
 	data *lua.LTable
+	// rec is nil when nothing is recorded
+	rec *recorder
+}
+
+// table returns a parser for a nested table sharing the recorder of p.
+func (p *LuaTableParser) table(t *lua.LTable) *LuaTableParser {
+	return &LuaTableParser{data: t, rec: p.rec}
+}
+
+func (p *LuaTableParser) get(key string) lua.LValue {
+	if p.rec != nil {
+		if p.rec.reads[p.data] == nil {
+			p.rec.reads[p.data] = make(map[string]bool)
+		}
+		p.rec.reads[p.data][key] = true
+	}
+	return p.data.RawGetString(key)
+}
+
+// typeError returns the error for a value of an unexpected type.
+func (p *LuaTableParser) typeError(key string, v lua.LValue, expected string) error {
+	return p.record(key, v, fmt.Errorf("incorrect lua type, expected %s but got '%s'", expected, v.Type()))
+}
+
+// record returns err, recording it for the linter when the key is set. Missing
+// keys aren't a problem, the default is used.
+func (p *LuaTableParser) record(key string, v lua.LValue, err error) error {
+	if err != nil && p.rec != nil && v != lua.LNil {
+		if p.rec.typeErrors[p.data] == nil {
+			p.rec.typeErrors[p.data] = make(map[string]error)
+		}
+		p.rec.typeErrors[p.data][key] = err
+	}
+	return err
 }
 
 func (p *LuaTableParser) String(key string) (s string, err error) {
-	v := p.data.RawGetString(key)
+	v := p.get(key)
 	if v.Type() != lua.LTString {
-		err = fmt.Errorf("incorrect lua type, expected 'LTString' but got '%s'", v.Type())
+		err = p.typeError(key, v, "'LTString'")
 		return
 	}
 	s = v.String()
@@ -23,9 +72,9 @@ func (p *LuaTableParser) String(key string) (s string, err error) {
 }
 
 func (p *LuaTableParser) ListString(key string) (s []string, err error) {
-	v := p.data.RawGetString(key)
+	v := p.get(key)
 	if v.Type() != lua.LTString {
-		err = fmt.Errorf("incorrect lua type, expected 'LTString' but got '%s'", v.Type())
+		err = p.typeError(key, v, "'LTString'")
 		return
 	}
 	list := v.String()
@@ -34,63 +83,146 @@ func (p *LuaTableParser) ListString(key string) (s []string, err error) {
 }
 
 func (p *LuaTableParser) Int(key string) (i int, err error) {
-	v := p.data.RawGetString(key)
+	v := p.get(key)
 	if v.Type() != lua.LTNumber && v.Type() != lua.LTString {
-		err = fmt.Errorf("incorrect lua type, expected 'LTString' or 'LTNumber' but got '%s'", v.Type())
+		err = p.typeError(key, v, "'LTString' or 'LTNumber'")
 		return
 	}
 	i, err = strconv.Atoi(v.String())
+	err = p.record(key, v, err)
 	return
 }
 
 func (p *LuaTableParser) Int64(key string) (i int64, err error) {
-	v := p.data.RawGetString(key)
+	v := p.get(key)
 	if v.Type() != lua.LTNumber && v.Type() != lua.LTString {
-		err = fmt.Errorf("incorrect lua type, expected 'LTString' or 'LTNumber' but got '%s'", v.Type())
+		err = p.typeError(key, v, "'LTString' or 'LTNumber'")
 		return
 	}
 	i, err = strconv.ParseInt(v.String(), 10, 64)
+	err = p.record(key, v, err)
 	return
 }
 
 func (p *LuaTableParser) OptionalInt(key string) (i *int, err error) {
-	v := p.data.RawGetString(key)
+	v := p.get(key)
 	if v.Type() != lua.LTNumber && v.Type() != lua.LTString {
-		err = fmt.Errorf("incorrect lua type, expected 'LTString' or 'LTNumber' but got '%s'", v.Type())
+		err = p.typeError(key, v, "'LTString' or 'LTNumber'")
 		return
 	}
 	var iVal int
 	iVal, err = strconv.Atoi(v.String())
+	err = p.record(key, v, err)
 	i = &iVal
 	return
 }
 
 func (p *LuaTableParser) Float64(key string) (f float64, err error) {
-	v := p.data.RawGetString(key)
+	v := p.get(key)
 	if v.Type() != lua.LTNumber && v.Type() != lua.LTString {
-		err = fmt.Errorf("incorrect lua type, expected 'LTString' or 'LTNumber' but got '%s'", v.Type())
+		err = p.typeError(key, v, "'LTString' or 'LTNumber'")
 		return
 	}
 	f, err = strconv.ParseFloat(v.String(), 64)
+	err = p.record(key, v, err)
 	return
 }
 
 func (p *LuaTableParser) Bool(key string) (b bool, err error) {
-	v := p.data.RawGetString(key)
+	v := p.get(key)
 	if v.Type() != lua.LTBool {
-		err = fmt.Errorf("incorrect lua type, expected 'LTBool' but got '%s'", v.Type())
+		err = p.typeError(key, v, "'LTBool'")
 		return
 	}
 	b, err = strconv.ParseBool(v.String())
 	return
 }
 
+// Flag reads a boolean that unit files write either as true/false or as 1/0.
+func (p *LuaTableParser) Flag(key string) (b bool, err error) {
+	v := p.get(key)
+	switch v.Type() {
+	case lua.LTBool:
+		b = lua.LVAsBool(v)
+	case lua.LTNumber:
+		b = float64(v.(lua.LNumber)) != 0
+	default:
+		err = p.typeError(key, v, "'LTBool' or 'LTNumber'")
+	}
+	return
+}
+
+// Has reports whether key is set.
+func (p *LuaTableParser) Has(key string) bool {
+	return p.get(key) != lua.LNil
+}
+
 func (p *LuaTableParser) Table(key string) (parser *LuaTableParser, err error) {
-	v := p.data.RawGetString(key)
+	v := p.get(key)
 	if v.Type() != lua.LTTable {
-		err = fmt.Errorf("incorrect lua type, expected 'LTTable' but got '%s'", v.Type())
+		err = p.typeError(key, v, "'LTTable'")
 		return
 	}
-	parser = &LuaTableParser{v.(*lua.LTable)}
+	parser = p.table(v.(*lua.LTable))
 	return
 }
+
+// Params reads a table keeping every value with its Lua type, functions and
+// other values that can't be generated are skipped.
+func (p *LuaTableParser) Params(key string) (params types.Params, err error) {
+	v := p.get(key)
+	t, ok := v.(*lua.LTable)
+	if !ok {
+		err = p.typeError(key, v, "'LTTable'")
+		return
+	}
+	params = paramsFromTable(t)
+	return
+}
+
+// tableFromParams turns params back into a Lua table, numeric keys become
+// array indices again.
+func tableFromParams(L *lua.LState, params types.Params) *lua.LTable {
+	t := L.NewTable()
+	for k, v := range params {
+		var lv lua.LValue
+		switch v := v.(type) {
+		case string:
+			lv = lua.LString(v)
+		case float64:
+			lv = lua.LNumber(v)
+		case int:
+			// Whole numbers are ints once the params are embedded as Go code
+			lv = lua.LNumber(v)
+		case bool:
+			lv = lua.LBool(v)
+		case types.Params:
+			lv = tableFromParams(L, v)
+		default:
+			continue
+		}
+		if i, err := strconv.Atoi(k); err == nil && i > 0 {
+			t.RawSetInt(i, lv)
+			continue
+		}
+		t.RawSetString(k, lv)
+	}
+	return t
+}
+
+func paramsFromTable(t *lua.LTable) types.Params {
+	params := make(types.Params)
+	t.ForEach(func(k lua.LValue, v lua.LValue) {
+		switch v := v.(type) {
+		case lua.LString:
+			params[k.String()] = string(v)
+		case lua.LNumber:
+			params[k.String()] = float64(v)
+		case lua.LBool:
+			params[k.String()] = bool(v)
+		case *lua.LTable:
+			params[k.String()] = paramsFromTable(v)
+		}
+	})
+	return params
+}
@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/wezzle/bar-unit-info/gamedata/luaenv"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// tweakSuffixes are the suffixes of the numbered tweak modoptions, lobbies can
+// set tweakdefs, tweakdefs1 up to tweakdefs9 and the same for tweakunits.
+var tweakSuffixes = []string{"", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
+
+// HasTweaks reports whether the modoptions contain tweakdefs or tweakunits.
+func HasTweaks() bool {
+	for _, suffix := range tweakSuffixes {
+		if modOptions["tweakdefs"+suffix] != "" || modOptions["tweakunits"+suffix] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodeTweak decodes a base64 encoded tweak as pasted in a lobby. Both the
+// standard and URL alphabets are accepted, with or without padding.
+func DecodeTweak(encoded string) (string, error) {
+	decoded, ok := luaenv.DecodeBase64(encoded)
+	if !ok {
+		return "", fmt.Errorf("tweak is not valid base64")
+	}
+	return decoded, nil
+}
+
+// ApplyTweaks runs the tweakdefs modoptions as Lua code with access to the
+// UnitDefs global and then merges the tweakunits modoptions into unitDefs.
+func ApplyTweaks(L *lua.LState, unitDefs *lua.LTable) error {
+	return applyTweaks(L, unitDefs, modOptions)
+}
+
+// TweakUnitDefs applies the tweakdefs and tweakunits in mo to the evaluated
+// unit definitions in raw and returns the properties of every unit. It works
+// without the game repo on the definitions embedded in the data, movement
+// classes are resolved with moveDefs.
+func TweakUnitDefs(raw map[types.UnitRef]types.RawUnitDef, mo types.ModOptions, moveDefs map[string]types.MoveDef) ([]types.UnitProperties, error) {
+	env := luaenv.New(emptyFS{})
+	defer env.Close()
+
+	refs := make([]types.UnitRef, 0, len(raw))
+	for ref := range raw {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	unitDefs := env.L.NewTable()
+	env.L.SetGlobal("UnitDefs", unitDefs)
+	for _, ref := range refs {
+		unitDefs.RawSetString(ref, tableFromParams(env.L, raw[ref].Def))
+	}
+	if err := applyTweaks(env.L, unitDefs, mo); err != nil {
+		return nil, err
+	}
+
+	unitProperties := make([]types.UnitProperties, 0, len(refs))
+	for _, ref := range refs {
+		data, ok := unitDefs.RawGetString(ref).(*lua.LTable)
+		if !ok {
+			continue
+		}
+		up, err := unitPropertiesFromTable(data, ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ref, err)
+		}
+		up.Source = sourceForFile(raw[ref].File, ref)
+		resolveMovement(&up.Movement, moveDefs)
+		unitProperties = append(unitProperties, *up)
+	}
+	return unitProperties, nil
+}
+
+// emptyFS is a file system without files, tweaks loaded at runtime are
+// evaluated without the game repo.
+type emptyFS struct{}
+
+func (emptyFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// IsTweakUnits reports whether a decoded tweak is a tweakunits table rather
+// than tweakdefs code.
+func IsTweakUnits(decoded string) bool {
+	return strings.HasPrefix(strings.TrimSpace(decoded), "{")
+}
+
+func applyTweaks(L *lua.LState, unitDefs *lua.LTable, mo types.ModOptions) error {
+	for _, suffix := range tweakSuffixes {
+		encoded := mo["tweakdefs"+suffix]
+		if encoded == "" {
+			continue
+		}
+		code, err := DecodeTweak(encoded)
+		if err != nil {
+			return fmt.Errorf("tweakdefs%s: %w", suffix, err)
+		}
+		if err := L.DoString(code); err != nil {
+			return fmt.Errorf("tweakdefs%s: %w", suffix, err)
+		}
+	}
+
+	for _, suffix := range tweakSuffixes {
+		encoded := mo["tweakunits"+suffix]
+		if encoded == "" {
+			continue
+		}
+		code, err := DecodeTweak(encoded)
+		if err != nil {
+			return fmt.Errorf("tweakunits%s: %w", suffix, err)
+		}
+		top := L.GetTop()
+		if err := L.DoString("return " + code); err != nil {
+			return fmt.Errorf("tweakunits%s: %w", suffix, err)
+		}
+		tweaks, ok := L.Get(-1).(*lua.LTable)
+		L.SetTop(top)
+		if !ok {
+			return fmt.Errorf("tweakunits%s: does not contain a lua table", suffix)
+		}
+		tweaks.ForEach(func(k lua.LValue, v lua.LValue) {
+			unitDef, ok := unitDefs.RawGetString(strings.ToLower(k.String())).(*lua.LTable)
+			tweak, isTable := v.(*lua.LTable)
+			if !ok || !isTable {
+				return
+			}
+			mergeTables(L, unitDef, tweak)
+		})
+	}
+	return nil
+}
+
+// mergeTables deep merges source into target, unit definition keys are
+// lowercase so keys of source are lowercased as well.
+func mergeTables(L *lua.LState, target *lua.LTable, source *lua.LTable) {
+	source.ForEach(func(k lua.LValue, v lua.LValue) {
+		key := k
+		if k.Type() == lua.LTString {
+			key = lua.LString(strings.ToLower(k.String()))
+		}
+		sourceTable, sourceIsTable := v.(*lua.LTable)
+		targetTable, targetIsTable := target.RawGet(key).(*lua.LTable)
+		if sourceIsTable && targetIsTable {
+			mergeTables(L, targetTable, sourceTable)
+			return
+		}
+		if sourceIsTable {
+			copied := L.NewTable()
+			mergeTables(L, copied, sourceTable)
+			v = copied
+		}
+		target.RawSet(key, v)
+	})
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const (
+	ScopeUnit      = "unit"
+	ScopeWeapon    = "weapon"
+	ScopeWeaponDef = "weapondef"
+)
+
+// UnknownKey is a key found in unit definitions that the parser doesn't map
+// into the unit properties.
+type UnknownKey struct {
+	Scope    string
+	Key      string
+	Count    int
+	Examples []types.UnitRef
+}
+
+// scopedTable is a table of a unit definition the parser reads as a whole.
+// Name is the key of the table within weapons or weapondefs.
+type scopedTable struct {
+	scope string
+	ref   types.UnitRef
+	file  string
+	name  string
+	table *lua.LTable
+}
+
+// collectTables returns the unit tables of defs with their weapons and weapon
+// definitions.
+func collectTables(defs []unitDef, unitDefs *lua.LTable) []scopedTable {
+	tables := make([]scopedTable, 0)
+	for _, def := range defs {
+		data, ok := unitDefs.RawGetString(def.ref).(*lua.LTable)
+		if !ok {
+			continue
+		}
+		tables = append(tables, scopedTable{ScopeUnit, def.ref, def.file, def.ref, data})
+		for _, nested := range []struct {
+			scope string
+			key   string
+		}{{ScopeWeapon, "weapons"}, {ScopeWeaponDef, "weapondefs"}} {
+			if t, ok := data.RawGetString(nested.key).(*lua.LTable); ok {
+				t.ForEach(func(k lua.LValue, v lua.LValue) {
+					if entry, ok := v.(*lua.LTable); ok {
+						tables = append(tables, scopedTable{nested.scope, def.ref, def.file, k.String(), entry})
+					}
+				})
+			}
+		}
+	}
+	return tables
+}
+
+// FindUnknownKeys evaluates every unit definition and reports the keys of unit
+// tables, weapons and weapon definitions the parser never reads, sorted by the
+// number of units using them. Examples lists up to maxExamples units.
+func FindUnknownKeys(maxExamples int) []UnknownKey {
+	env := newLuaEnv()
+	defer env.Close()
+
+	defs, unitDefs, err := evaluateUnitDefs(env, false)
+	if err != nil {
+		return nil
+	}
+
+	rec := newRecorder()
+	tables := collectTables(defs, unitDefs)
+	for _, st := range tables {
+		if st.scope == ScopeUnit {
+			readUnitProperties(&LuaTableParser{data: st.table, rec: rec}, st.ref)
+		}
+	}
+
+	// Keys are known for a scope when they're read from any of its tables,
+	// some keys are only read when another key is missing
+	known := make(map[string]map[string]bool)
+	for _, st := range tables {
+		if known[st.scope] == nil {
+			known[st.scope] = make(map[string]bool)
+		}
+		for key := range rec.reads[st.table] {
+			known[st.scope][key] = true
+		}
+	}
+
+	unknown := make(map[[2]string]*UnknownKey)
+	seen := make(map[[3]string]bool)
+	for _, st := range tables {
+		st.table.ForEach(func(k lua.LValue, _ lua.LValue) {
+			if k.Type() != lua.LTString || known[st.scope][k.String()] {
+				return
+			}
+			id := [2]string{st.scope, k.String()}
+			// Count units, not the weapons of a unit
+			if seen[[3]string{st.scope, k.String(), st.ref}] {
+				return
+			}
+			seen[[3]string{st.scope, k.String(), st.ref}] = true
+			uk, ok := unknown[id]
+			if !ok {
+				uk = &UnknownKey{Scope: st.scope, Key: k.String()}
+				unknown[id] = uk
+			}
+			uk.Count++
+			uk.Examples = append(uk.Examples, st.ref)
+		})
+	}
+
+	result := make([]UnknownKey, 0, len(unknown))
+	for _, uk := range unknown {
+		sort.Strings(uk.Examples)
+		if len(uk.Examples) > max(0, maxExamples) {
+			uk.Examples = uk.Examples[:max(0, maxExamples)]
+		}
+		result = append(result, *uk)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		if result[i].Scope != result[j].Scope {
+			return result[i].Scope < result[j].Scope
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-zglob"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// LoadWeaponDefs loads the shared weapon definitions from the repo's weapons
+// directory, such as the death explosions referenced by explodeas and
+// selfdestructas. Every file returns a table of definitions by name, names are
+// lowercased as the engine matches them case insensitive.
+func LoadWeaponDefs() map[string]types.WeaponDef {
+	files, err := zglob.Glob(filepath.Join(os.Getenv("GAME_REPO"), "weapons/**/*.lua"))
+	if err != nil {
+		slog.Error("failed to glob", "error", err)
+		return nil
+	}
+
+	env := newLuaEnv()
+	defer env.Close()
+
+	defs := make(map[string]types.WeaponDef)
+	for _, f := range files {
+		top := env.L.GetTop()
+		if err := env.DoFile(repoRelativePath(f)); err != nil {
+			slog.Error("failed to evaluate weapon file", "file", f, "error", err)
+			env.L.SetTop(top)
+			continue
+		}
+		t, ok := env.L.Get(-1).(*lua.LTable)
+		env.L.SetTop(top)
+		if !ok {
+			slog.Error("weapon file does not contain a lua table", "file", f)
+			continue
+		}
+		t.ForEach(func(k lua.LValue, v lua.LValue) {
+			if def, ok := v.(*lua.LTable); ok {
+				defs[strings.ToLower(k.String())] = parseWeaponDef(&LuaTableParser{data: def})
+			}
+		})
+	}
+	reportMissing(env, "weapon definitions")
+	return defs
+}
@@ -0,0 +1,115 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+type PropertyDiff struct {
+	// Path is the dot separated field path, e.g. "Health" or
+	// "WeaponDefs.gun.Range".
+	Path  string
+	Old   string
+	New   string
+	Added bool
+}
+
+// DiffUnitProperties returns every field that differs between a and b.
+func DiffUnitProperties(a *UnitProperties, b *UnitProperties) []PropertyDiff {
+	diffs := make([]PropertyDiff, 0)
+	diffValues("", reflect.ValueOf(*a), reflect.ValueOf(*b), &diffs)
+	return diffs
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func diffValues(path string, a reflect.Value, b reflect.Value, diffs *[]PropertyDiff) {
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			d := PropertyDiff{Path: path, Added: !a.IsValid()}
+			if a.IsValid() {
+				d.Old = fmt.Sprint(a.Interface())
+			}
+			if b.IsValid() {
+				d.New = fmt.Sprint(b.Interface())
+			}
+			*diffs = append(*diffs, d)
+		}
+		return
+	}
+
+	if a.Kind() != b.Kind() {
+		// Embedded params hold whole numbers as ints
+		if af, ok := numberValue(a); ok {
+			if bf, ok := numberValue(b); ok && af == bf {
+				return
+			}
+		}
+		*diffs = append(*diffs, PropertyDiff{Path: path, Old: fmt.Sprint(a.Interface()), New: fmt.Sprint(b.Interface())})
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := range a.NumField() {
+			diffValues(joinPath(path, a.Type().Field(i).Name), a.Field(i), b.Field(i), diffs)
+		}
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, k := range a.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		for _, k := range b.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		names := make([]string, 0)
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			diffValues(joinPath(path, name), a.MapIndex(keys[name]), b.MapIndex(keys[name]), diffs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range max(a.Len(), b.Len()) {
+			var aItem, bItem reflect.Value
+			if i < a.Len() {
+				aItem = a.Index(i)
+			}
+			if i < b.Len() {
+				bItem = b.Index(i)
+			}
+			diffValues(joinPath(path, fmt.Sprint(i)), aItem, bItem, diffs)
+		}
+	case reflect.Interface, reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				*diffs = append(*diffs, PropertyDiff{Path: path, Old: fmt.Sprint(a.Interface()), New: fmt.Sprint(b.Interface())})
+			}
+			return
+		}
+		diffValues(path, a.Elem(), b.Elem(), diffs)
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*diffs = append(*diffs, PropertyDiff{Path: path, Old: fmt.Sprint(a.Interface()), New: fmt.Sprint(b.Interface())})
+		}
+	}
+}
+
+func numberValue(v reflect.Value) (float64, bool) {
+	switch {
+	case v.CanInt():
+		return float64(v.Int()), true
+	case v.CanUint():
+		return float64(v.Uint()), true
+	case v.CanFloat():
+		return v.Float(), true
+	}
+	return 0, false
+}
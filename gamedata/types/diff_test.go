@@ -0,0 +1,99 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffUnitProperties(t *testing.T) {
+	stock := func() *UnitProperties {
+		return &UnitProperties{
+			Ref:          "armpw",
+			Health:       300,
+			BuildOptions: []UnitRef{"armmex"},
+			WeaponDefs: map[string]WeaponDef{
+				"gun": {Name: "Gun", Damage: Damage{"default": 10, "vtol": 2}},
+			},
+			Weapons: []Weapon{{Def: "GUN", OnlyTargetCategory: "NOTSUB"}},
+			Wreck:   FeatureDef{Metal: 25},
+			CustomParams: CustomParams{
+				TechLevel: 1,
+				Params:    Params{"model_author": "someone", "decoration": Params{"size": 2}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		tweak func(up *UnitProperties)
+		want  []PropertyDiff
+	}{
+		{
+			name:  "unchanged",
+			tweak: func(up *UnitProperties) {},
+			want:  []PropertyDiff{},
+		},
+		{
+			name:  "field",
+			tweak: func(up *UnitProperties) { up.Health = 999 },
+			want:  []PropertyDiff{{Path: "Health", Old: "300", New: "999"}},
+		},
+		{
+			name:  "nested struct",
+			tweak: func(up *UnitProperties) { up.Wreck.Metal = 30; up.CustomParams.TechLevel = 2 },
+			want: []PropertyDiff{
+				{Path: "Wreck.Metal", Old: "25", New: "30"},
+				{Path: "CustomParams.TechLevel", Old: "1", New: "2"},
+			},
+		},
+		{
+			name:  "slice item added",
+			tweak: func(up *UnitProperties) { up.BuildOptions = append(up.BuildOptions, "armsolar") },
+			want:  []PropertyDiff{{Path: "BuildOptions.1", New: "armsolar", Added: true}},
+		},
+		{
+			name:  "slice item removed",
+			tweak: func(up *UnitProperties) { up.BuildOptions = nil },
+			want:  []PropertyDiff{{Path: "BuildOptions.0", Old: "armmex"}},
+		},
+		{
+			name:  "struct in slice",
+			tweak: func(up *UnitProperties) { up.Weapons = []Weapon{{Def: "GUN", OnlyTargetCategory: "VTOL"}} },
+			want:  []PropertyDiff{{Path: "Weapons.0.OnlyTargetCategory", Old: "NOTSUB", New: "VTOL"}},
+		},
+		{
+			name: "map in map",
+			tweak: func(up *UnitProperties) {
+				up.WeaponDefs = map[string]WeaponDef{"gun": {Name: "Gun", Damage: Damage{"default": 15, "vtol": 2}}}
+			},
+			want: []PropertyDiff{{Path: "WeaponDefs.gun.Damage.default", Old: "10", New: "15"}},
+		},
+		{
+			name: "nested params",
+			tweak: func(up *UnitProperties) {
+				up.CustomParams.Params = Params{"model_author": "someone", "decoration": Params{"size": 3, "model": "pawn"}}
+			},
+			want: []PropertyDiff{
+				{Path: "CustomParams.Params.decoration.model", New: "pawn", Added: true},
+				{Path: "CustomParams.Params.decoration.size", Old: "2", New: "3"},
+			},
+		},
+		{
+			name: "whole number params",
+			tweak: func(up *UnitProperties) {
+				up.CustomParams.Params = Params{"model_author": "someone", "decoration": Params{"size": 2.0}}
+			},
+			want: []PropertyDiff{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tweaked := stock()
+			tt.tweak(tweaked)
+			got := DiffUnitProperties(stock(), tweaked)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffUnitProperties() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
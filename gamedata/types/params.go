@@ -0,0 +1,84 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Value returns the value at a dot separated path, e.g. "paralyzemultiplier"
+// or "decoration.model".
+func (p Params) Value(path string) (interface{}, bool) {
+	key, rest, nested := strings.Cut(path, ".")
+	v, ok := p[key]
+	if !ok || !nested {
+		return v, ok
+	}
+	sub, ok := v.(Params)
+	if !ok {
+		return nil, false
+	}
+	return sub.Value(rest)
+}
+
+// Float returns a number at path, numeric strings are converted as Lua would.
+func (p Params) Float(path string) (float64, bool) {
+	v, ok := p.Value(path)
+	if !ok {
+		return 0, false
+	}
+	return ParamFloat(v)
+}
+
+// ParamFloat converts a param value to a number.
+func ParamFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// FormatParam formats a param value the way it's written in Lua.
+func FormatParam(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case Params:
+		return fmt.Sprintf("{%d}", len(v))
+	}
+	return fmt.Sprint(v)
+}
+
+// Flatten returns the params as sorted dot separated paths with their
+// formatted values, nested tables are expanded.
+func (p Params) Flatten() [][2]string {
+	flat := make([][2]string, 0)
+	p.flatten("", &flat)
+	sort.Slice(flat, func(i, j int) bool {
+		return flat[i][0] < flat[j][0]
+	})
+	return flat
+}
+
+func (p Params) flatten(prefix string, flat *[][2]string) {
+	for k, v := range p {
+		if sub, ok := v.(Params); ok && len(sub) > 0 {
+			sub.flatten(prefix+k+".", flat)
+			continue
+		}
+		*flat = append(*flat, [2]string{prefix + k, FormatParam(v)})
+	}
+}
@@ -0,0 +1,38 @@
+package types
+
+// WithFallback returns a copy of t with the names and texts t doesn't
+// translate taken from fallback.
+func (t Translations) WithFallback(fallback Translations) Translations {
+	u, f := t.Units, fallback.Units
+	fallbackString := func(s *string, fs string) {
+		if *s == "" {
+			*s = fs
+		}
+	}
+	fallbackString(&u.Dead, f.Dead)
+	fallbackString(&u.Heap, f.Heap)
+	fallbackString(&u.DecoyCommanderNameTag, f.DecoyCommanderNameTag)
+	fallbackString(&u.Scavenger, f.Scavenger)
+	fallbackString(&u.ScavCommanderNameTag, f.ScavCommanderNameTag)
+	fallbackString(&u.ScavDecoyCommanderNameTag, f.ScavDecoyCommanderNameTag)
+	u.Factions = mergeStrings(u.Factions, f.Factions)
+	u.Names = mergeStrings(u.Names, f.Names)
+	u.Descriptions = mergeStrings(u.Descriptions, f.Descriptions)
+	t.Units = u
+	return t
+}
+
+// mergeStrings returns the values of m, missing or empty keys are taken from
+// fallback.
+func mergeStrings(m map[string]string, fallback map[string]string) map[string]string {
+	merged := make(map[string]string, len(fallback))
+	for k, v := range fallback {
+		merged[k] = v
+	}
+	for k, v := range m {
+		if v != "" {
+			merged[k] = v
+		}
+	}
+	return merged
+}
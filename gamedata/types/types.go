@@ -11,6 +11,9 @@ type (
 	Lab                 = UnitRef
 	LabGrid             map[Lab]GridRow
 	WeaponType          = string
+	UnitSource          = string
+	Domain              = string
+	ModOptions          map[string]string
 	Damage              map[string]float64
 	ScarIndices         struct{}
 	Shield              struct {
@@ -38,7 +41,7 @@ type (
 		Name                     string
 		WeaponType               WeaponType
 		Id                       int64
-		CustomParams             map[string]interface{}
+		CustomParams             Params
 		AvoidFriendly            bool
 		AvoidFeature             bool
 		AvoidNeutral             bool
@@ -191,12 +194,73 @@ type (
 		SoundHitDryVolume        float64
 		SoundHitWetVolume        float64
 	}
+	// RawUnitDef is a unit definition table as evaluated by the game and the
+	// repo relative file it is defined in.
+	RawUnitDef struct {
+		File string
+		Def  Params
+	}
+	// MoveDef is a movement class from gamedata/movedefs.lua shared by units
+	// with the same movementclass.
+	MoveDef struct {
+		Name string
+		// SpeedModClass is 0 for tanks, 1 for bots, 2 for hovers and 3 for ships
+		SpeedModClass int64
+		CrushStrength float64
+		MaxSlope      float64
+		MaxWaterDepth float64
+		MinWaterDepth float64
+		Submarine     bool
+	}
+	// Movement holds how a unit moves, slope and water depth limits are
+	// resolved through the movement class when the unit doesn't set them.
+	Movement struct {
+		MovementClass  string
+		MoveDef        MoveDef
+		MaxAcc         float64
+		MaxDec         float64
+		TurnRate       float64
+		MaxSlope       float64
+		MaxWaterDepth  float64
+		MinWaterDepth  float64
+		CanFly         bool
+		CruiseAltitude float64
+		CanHover       bool
+		Amphibious     bool
+	}
+	Abilities struct {
+		CanCloak          bool
+		CloakCost         float64
+		CloakCostMoving   float64
+		Stealth           bool
+		SonarStealth      bool
+		CanResurrect      bool
+		CanReclaim        bool
+		CanCapture        bool
+		CanRepair         bool
+		OnOffable         bool
+		TransportCapacity int64
+		BuildDistance     float64
+	}
+	// FeatureDef is the corpse a unit leaves, the dead wreck or the heap left
+	// when the wreck is destroyed.
+	FeatureDef struct {
+		Metal         float64
+		Energy        float64
+		Damage        float64
+		Resurrectable bool
+	}
+	// Params holds customparams with their Lua types: string, float64 (int
+	// after generation for whole numbers), bool or a nested Params table.
+	Params       map[string]interface{}
 	CustomParams struct {
 		TechLevel int
 		UnitGroup string
+		Params    Params
 	}
 	UnitProperties struct {
 		Ref            UnitRef
+		Source         UnitSource
 		MetalCost      int64
 		EnergyCost     int64
 		Buildtime      int64
@@ -204,12 +268,18 @@ type (
 		Health         int64
 		SightDistance  int64
 		Speed          float64
+		Movement       Movement
 		Buildpower     int64
+		Abilities      Abilities
 		SonarDistance  int64
 		RadarDistance  int64
 		JammerDistance int64
 		WeaponDefs     map[string]WeaponDef
 		Weapons        []Weapon
+		ExplodeAs      string
+		SelfDestructAs string
+		Wreck          FeatureDef
+		Heap           FeatureDef
 		CustomParams   CustomParams
 	}
 	Translations struct {
@@ -226,3 +296,18 @@ type (
 		} `json:"units"`
 	}
 )
+
+const (
+	SourceStock      UnitSource = "stock"
+	SourceScavengers UnitSource = "scavengers"
+	SourceRaptors    UnitSource = "raptors"
+)
+
+const (
+	DomainLand   Domain = "land"
+	DomainHover  Domain = "hover"
+	DomainAmphib Domain = "amphib"
+	DomainSea    Domain = "sea"
+	DomainSub    Domain = "sub"
+	DomainAir    Domain = "air"
+)
@@ -81,23 +81,9 @@ func (p *UnitProperties) DPS() float64 {
 			continue
 		}
 
-		if sweepFire, exists := wd.CustomParams["sweepfire"]; exists {
-			var sweepFireValue float64
-			switch v := sweepFire.(type) {
-			case float64:
-				sweepFireValue = v
-			case float32:
-				sweepFireValue = float64(v)
-			case int64:
-				sweepFireValue = float64(v)
-			case int:
-				sweepFireValue = float64(v)
-			}
-
-			if sweepFireValue != 0 {
-				dps = dps + (damage * sweepFireValue)
-				continue
-			}
+		if sweepFireValue, exists := wd.CustomParams.Float("sweepfire"); exists && sweepFireValue != 0 {
+			dps = dps + (damage * sweepFireValue)
+			continue
 		}
 
 		damage = damage / wd.ReloadTime
@@ -160,6 +146,75 @@ func (p *UnitProperties) ParalyzeTime() int64 {
 	return time
 }
 
+func (p *UnitProperties) IsPvE() bool {
+	return p.Source == SourceScavengers || p.Source == SourceRaptors
+}
+
+// AbilityNames returns the names of the unit's special abilities, names don't
+// contain spaces so they can be used as filter terms.
+func (p *UnitProperties) AbilityNames() []string {
+	a := p.Abilities
+	names := make([]string, 0)
+	for _, ability := range []struct {
+		name string
+		has  bool
+	}{
+		{"builder", p.Buildpower != 0},
+		{"reclaim", a.CanReclaim},
+		{"repair", a.CanRepair},
+		{"resurrect", a.CanResurrect},
+		{"capture", a.CanCapture},
+		{"cloak", a.CanCloak},
+		{"stealth", a.Stealth},
+		{"sonar-stealth", a.SonarStealth},
+		{"transport", a.TransportCapacity != 0},
+		{"on/off", a.OnOffable},
+	} {
+		if ability.has {
+			names = append(names, ability.name)
+		}
+	}
+	return names
+}
+
+// Domain returns where a unit can move, buildings are placed on land or on
+// water depending on their minimum water depth.
+func (p *UnitProperties) Domain() Domain {
+	m := p.Movement
+	switch {
+	case m.CanFly:
+		return DomainAir
+	case m.CanHover || m.MoveDef.SpeedModClass == 2:
+		return DomainHover
+	case m.MoveDef.Submarine:
+		return DomainSub
+	case m.MoveDef.SpeedModClass == 3 || m.MinWaterDepth > 0:
+		return DomainSea
+	case m.Amphibious:
+		return DomainAmphib
+	}
+	return DomainLand
+}
+
 func (p *UnitProperties) IsBuilding() bool {
 	return p.Speed == 0
 }
+
+// Clone returns a copy of the unit properties that can be changed without
+// affecting the original, weapon definitions and their damage are copied too.
+func (p *UnitProperties) Clone() *UnitProperties {
+	c := *p
+	c.BuildOptions = append([]UnitRef(nil), p.BuildOptions...)
+	c.Weapons = append([]Weapon(nil), p.Weapons...)
+	if p.WeaponDefs != nil {
+		c.WeaponDefs = make(map[string]WeaponDef, len(p.WeaponDefs))
+		for name, wd := range p.WeaponDefs {
+			wd.Damage = make(Damage, len(p.WeaponDefs[name].Damage))
+			for k, v := range p.WeaponDefs[name].Damage {
+				wd.Damage[k] = v
+			}
+			c.WeaponDefs[name] = wd
+		}
+	}
+	return &c
+}
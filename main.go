@@ -1,11 +1,15 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/wezzle/bar-unit-info/gamedata"
 	"github.com/wezzle/bar-unit-info/model"
+	"github.com/wezzle/bar-unit-info/util"
 )
 
 // //go:embed bar-repo/luaui bar-repo/units bar-repo/language
@@ -23,6 +27,43 @@ func main() {
 	// fmt.Printf("%+v\n", p.CustomParams)
 	// return
 
+	flag.Usage = usage
+	lang := flag.String("lang", gamedata.DefaultLanguage, "language of unit names and descriptions")
+	tweaks := flag.String("tweaks", "", "file with tweakdefs and tweakunits to apply, as JSON modoptions or one base64 string per line")
+	flag.Parse()
+	if !util.SetLanguage(*lang) {
+		fmt.Fprintf(os.Stderr, "Error: no translations for language %q, available: %s\n", *lang, strings.Join(gamedata.Languages(), ", "))
+		os.Exit(2)
+	}
+	if err := util.LoadAliases(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error loading aliases:", err)
+		os.Exit(1)
+	}
+	if *tweaks != "" {
+		mo, err := util.ReadTweaksFile(*tweaks)
+		if err == nil {
+			_, err = util.LoadTweaks(mo)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading tweaks:", err)
+			os.Exit(1)
+		}
+		gamedata.UseTweakedUnitProperties(true)
+	}
+
+	if args := flag.Args(); len(args) > 0 {
+		c, ok := commands[args[0]]
+		if !ok {
+			usage()
+			os.Exit(2)
+		}
+		if err := c.run(args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	m := model.NewMainModel()
 	if _, err := tea.NewProgram(m).Run(); err != nil {
 		fmt.Println("Error running program:", err)
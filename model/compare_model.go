@@ -2,6 +2,7 @@ package model
 
 import (
 	"os"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -38,13 +39,13 @@ var compareKeys = CompareKeyMap{
 		key.WithHelp("?", "toggle help"),
 	),
 	Quit: key.NewBinding(
-		key.WithKeys("q", "ctrl+c"),
-		key.WithHelp("q", "quit"),
+		key.WithKeys("q", "esc"),
+		key.WithHelp("q/esc", "back"),
 	),
 }
 
-func NewCompareModel(mainModel *MainModel, refs ...string) CompareModel {
-	m := CompareModel{
+func NewCompareModel(mainModel *MainModel, refs ...string) *CompareModel {
+	m := &CompareModel{
 		mainModel:  mainModel,
 		UnitModels: make([]*Unit, 0),
 	}
@@ -73,13 +74,14 @@ func NewCompareModel(mainModel *MainModel, refs ...string) CompareModel {
 	components := make([]string, 0)
 	for _, r := range refs {
 		um := NewUnitModel(r, mainModel, bv)
+		um.hideLinks = true
 		m.UnitModels = append(m.UnitModels, um)
-		components = append(components, paddingStyle.Render(um.View()))
+		components = append(components, paddingStyle.Render(um.renderContent()))
 	}
 	m.content = lipgloss.JoinHorizontal(lipgloss.Top, components...)
 
 	width, height, _ := term.GetSize(int(os.Stdout.Fd()))
-	m.viewport = viewport.New(width, height)
+	m.viewport = viewport.New(width, height-breadcrumbHeight)
 	m.viewport.SetContent(m.content)
 	m.ready = true
 
@@ -95,11 +97,21 @@ type CompareModel struct {
 	content   string
 }
 
-func (m CompareModel) Init() tea.Cmd {
+func (m *CompareModel) Init() tea.Cmd {
 	return nil
 }
 
-func (m CompareModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m *CompareModel) wheelScrolled() {}
+
+func (m *CompareModel) Title() string {
+	names := make([]string, 0, len(m.UnitModels))
+	for _, um := range m.UnitModels {
+		names = append(names, um.Title())
+	}
+	return "Compare " + strings.Join(names, " vs ")
+}
+
+func (m *CompareModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var (
 		cmd  tea.Cmd
 		cmds []tea.Cmd
@@ -108,10 +120,7 @@ func (m CompareModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, compareKeys.Quit):
-			return m.mainModel.TableModel, cmd
-		}
-		if k := msg.String(); k == "ctrl+c" || k == "q" || k == "esc" {
-			return m, tea.Quit
+			return m, back
 		}
 
 	case tea.WindowSizeMsg:
@@ -139,7 +148,7 @@ func (m CompareModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
-func (m CompareModel) View() string {
+func (m *CompareModel) View() string {
 	if !m.ready {
 		return "\n  Initializing..."
 	}
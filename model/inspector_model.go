@@ -0,0 +1,447 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+	"github.com/wezzle/bar-unit-info/util"
+)
+
+var (
+	inspectorCursorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	inspectorMatchStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("6"))
+	inspectorKeyStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+)
+
+type InspectorKeyMap struct {
+	Up         key.Binding
+	Down       key.Binding
+	Expand     key.Binding
+	Collapse   key.Binding
+	Toggle     key.Binding
+	Search     key.Binding
+	NextMatch  key.Binding
+	PrevMatch  key.Binding
+	CopyValue  key.Binding
+	CopyPath   key.Binding
+	Help       key.Binding
+	Quit       key.Binding
+	SearchDone key.Binding
+	SearchQuit key.Binding
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view. It's part
+// of the key.Map interface.
+func (k InspectorKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Toggle, k.Search, k.NextMatch, k.CopyValue, k.Help, k.Quit}
+}
+
+// FullHelp returns keybindings for the expanded help view. It's part of the
+// key.Map interface.
+func (k InspectorKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Expand, k.Collapse, k.Toggle},
+		{k.Search, k.NextMatch, k.PrevMatch},
+		{k.CopyValue, k.CopyPath, k.Help, k.Quit},
+	}
+}
+
+var inspectorKeys = InspectorKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "down"),
+	),
+	Expand: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→/l", "expand"),
+	),
+	Collapse: key.NewBinding(
+		key.WithKeys("left", "h"),
+		key.WithHelp("←/h", "collapse"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys("enter", spacebar),
+		key.WithHelp("<enter>", "toggle table"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search"),
+	),
+	NextMatch: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "next match"),
+	),
+	PrevMatch: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "previous match"),
+	),
+	CopyValue: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "copy value"),
+	),
+	CopyPath: key.NewBinding(
+		key.WithKeys("Y"),
+		key.WithHelp("Y", "copy path"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "esc"),
+		key.WithHelp("q/esc", "back"),
+	),
+	SearchDone: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("<enter>", "confirm search"),
+	),
+	SearchQuit: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("<esc>", "cancel search"),
+	),
+}
+
+// inspectorLine is a visible entry of the unit definition tree.
+type inspectorLine struct {
+	path  string
+	key   string
+	value interface{}
+	depth int
+}
+
+func (l inspectorLine) isTable() bool {
+	_, ok := l.value.(types.Params)
+	return ok
+}
+
+func NewInspectorModel(ref types.UnitRef, mainModel *MainModel) *Inspector {
+	raw, _ := gamedata.GetRawUnitDef(ref)
+
+	ti := textinput.New()
+	ti.Prompt = "/ "
+	ti.CharLimit = 64
+	ti.Width = 30
+
+	m := &Inspector{
+		ref:       ref,
+		name:      util.NameForRef(ref),
+		raw:       raw,
+		expanded:  make(map[string]bool),
+		height:    30,
+		search:    ti,
+		mainModel: mainModel,
+		help:      help.New(),
+	}
+	m.rebuild()
+	return m
+}
+
+// Inspector shows the evaluated unit definition as a collapsible tree.
+type Inspector struct {
+	ref      types.UnitRef
+	name     string
+	raw      types.RawUnitDef
+	expanded map[string]bool
+	lines    []inspectorLine
+	cursor   int
+	offset   int
+	height   int
+
+	search    textinput.Model
+	searching bool
+	status    string
+
+	mainModel *MainModel
+	help      help.Model
+}
+
+// sortedParamKeys orders array indices numerically before named keys.
+func sortedParamKeys(p types.Params) []string {
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, aErr := strconv.Atoi(keys[i])
+		b, bErr := strconv.Atoi(keys[j])
+		switch {
+		case aErr == nil && bErr == nil:
+			return a < b
+		case aErr == nil || bErr == nil:
+			return aErr == nil
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func joinInspectorPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// rebuild recomputes the visible lines from the expanded tables.
+func (m *Inspector) rebuild() {
+	m.lines = m.lines[:0]
+	var walk func(p types.Params, path string, depth int)
+	walk = func(p types.Params, path string, depth int) {
+		for _, k := range sortedParamKeys(p) {
+			line := inspectorLine{path: joinInspectorPath(path, k), key: k, value: p[k], depth: depth}
+			m.lines = append(m.lines, line)
+			if sub, ok := p[k].(types.Params); ok && m.expanded[line.path] {
+				walk(sub, line.path, depth+1)
+			}
+		}
+	}
+	walk(m.raw.Def, "", 0)
+	m.moveCursor(0)
+}
+
+func (m *Inspector) moveCursor(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.lines)-1))
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+func (m *Inspector) current() (inspectorLine, bool) {
+	if m.cursor >= len(m.lines) {
+		return inspectorLine{}, false
+	}
+	return m.lines[m.cursor], true
+}
+
+// matches reports whether a line's path or value contains the search query.
+func (m *Inspector) matches(path string, value interface{}) bool {
+	q := strings.ToLower(m.search.Value())
+	if q == "" {
+		return false
+	}
+	if strings.Contains(strings.ToLower(path), q) {
+		return true
+	}
+	if _, ok := value.(types.Params); ok {
+		return false
+	}
+	return strings.Contains(strings.ToLower(types.FormatParam(value)), q)
+}
+
+// expandMatches expands every table containing a match so all matches are
+// visible and returns the number of matches.
+func (m *Inspector) expandMatches() int {
+	count := 0
+	var walk func(p types.Params, path string) bool
+	walk = func(p types.Params, path string) bool {
+		found := false
+		for k, v := range p {
+			childPath := joinInspectorPath(path, k)
+			if m.matches(childPath, v) {
+				count++
+				found = true
+			}
+			if sub, ok := v.(types.Params); ok && walk(sub, childPath) {
+				m.expanded[childPath] = true
+				found = true
+			}
+		}
+		return found
+	}
+	walk(m.raw.Def, "")
+	return count
+}
+
+// jumpToMatch moves the cursor to the next visible match in direction dir.
+func (m *Inspector) jumpToMatch(dir int) {
+	for i := 1; i <= len(m.lines); i++ {
+		j := ((m.cursor+dir*i)%len(m.lines) + len(m.lines)) % len(m.lines)
+		if m.matches(m.lines[j].path, m.lines[j].value) {
+			m.moveCursor(j - m.cursor)
+			return
+		}
+	}
+}
+
+func (m *Inspector) copy(s string, what string) {
+	if err := clipboard.WriteAll(s); err != nil {
+		m.status = fmt.Sprintf("failed to copy %s: %s", what, err)
+		return
+	}
+	m.status = fmt.Sprintf("copied %s of %s", what, m.lines[m.cursor].path)
+}
+
+func (m *Inspector) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Inspector) Title() string {
+	return "Definition"
+}
+
+func (m *Inspector) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if m.searching {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(msg, inspectorKeys.SearchDone):
+				m.searching = false
+				m.search.Blur()
+				count := m.expandMatches()
+				m.rebuild()
+				m.status = fmt.Sprintf("%d matches", count)
+				if count > 0 && !m.matches(m.lines[m.cursor].path, m.lines[m.cursor].value) {
+					m.jumpToMatch(1)
+				}
+				return m, cmd
+			case key.Matches(msg, inspectorKeys.SearchQuit):
+				m.searching = false
+				m.search.Blur()
+				m.search.SetValue("")
+				m.status = ""
+				return m, cmd
+			}
+		}
+		m.search, cmd = m.search.Update(msg)
+		return m, cmd
+	}
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = max(5, msg.Height-8)
+		m.moveCursor(0)
+	case tea.KeyMsg:
+		m.status = ""
+		line, ok := m.current()
+		switch {
+		case key.Matches(msg, inspectorKeys.Quit):
+			return m, back
+		case key.Matches(msg, inspectorKeys.Help):
+			m.help.ShowAll = !m.help.ShowAll
+		case key.Matches(msg, inspectorKeys.Up):
+			m.moveCursor(-1)
+		case key.Matches(msg, inspectorKeys.Down):
+			m.moveCursor(1)
+		case key.Matches(msg, inspectorKeys.Expand):
+			if ok && line.isTable() {
+				m.expanded[line.path] = true
+				m.rebuild()
+			}
+		case key.Matches(msg, inspectorKeys.Collapse):
+			if !ok {
+				break
+			}
+			if line.isTable() && m.expanded[line.path] {
+				delete(m.expanded, line.path)
+				m.rebuild()
+				break
+			}
+			// Jump to the parent table
+			for i := m.cursor - 1; i >= 0; i-- {
+				if m.lines[i].depth < line.depth {
+					m.moveCursor(i - m.cursor)
+					break
+				}
+			}
+		case key.Matches(msg, inspectorKeys.Toggle):
+			if ok && line.isTable() {
+				m.expanded[line.path] = !m.expanded[line.path]
+				m.rebuild()
+			}
+		case key.Matches(msg, inspectorKeys.Search):
+			m.searching = true
+			m.search.Focus()
+			return m, textinput.Blink
+		case key.Matches(msg, inspectorKeys.NextMatch):
+			m.jumpToMatch(1)
+		case key.Matches(msg, inspectorKeys.PrevMatch):
+			m.jumpToMatch(-1)
+		case key.Matches(msg, inspectorKeys.CopyValue):
+			if ok {
+				m.copy(strings.Trim(types.FormatParam(line.value), `"`), "value")
+			}
+		case key.Matches(msg, inspectorKeys.CopyPath):
+			if ok {
+				m.copy(line.path, "path")
+			}
+		}
+	}
+	return m, cmd
+}
+
+func (m *Inspector) View() string {
+	var sections []string
+
+	sections = append(sections, lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().
+			Background(lipgloss.Color("57")).
+			Foreground(lipgloss.Color("230")).
+			Padding(0, 1).
+			Margin(0, 4, 0, 0).
+			Render(fmt.Sprintf("Definition: %s", m.name)),
+		lipgloss.NewStyle().
+			Background(lipgloss.Color("236")).
+			Foreground(lipgloss.Color("246")).
+			Padding(0, 1).
+			Render(m.ref),
+	))
+	if m.raw.File == "" {
+		sections = append(sections, descriptionStyle.Render("No unit definition was generated for this unit."))
+		sections = append(sections, padding.Render(m.help.View(inspectorKeys)))
+		return lipgloss.JoinVertical(lipgloss.Left, sections...)
+	}
+	sections = append(sections, descriptionStyle.Render(m.raw.File))
+
+	lines := make([]string, 0)
+	for i := m.offset; i < min(len(m.lines), m.offset+m.height); i++ {
+		l := m.lines[i]
+		marker := "  "
+		value := types.FormatParam(l.value)
+		if l.isTable() {
+			marker = "▸ "
+			if m.expanded[l.path] {
+				marker = "▾ "
+				value = ""
+			}
+		}
+		text := fmt.Sprintf("%s%s%s %s", strings.Repeat("  ", l.depth), marker, inspectorKeyStyle.Render(l.key+" ="), value)
+		switch {
+		case i == m.cursor:
+			text = inspectorCursorStyle.Render(fmt.Sprintf("%s%s%s = %s", strings.Repeat("  ", l.depth), marker, l.key, value))
+		case m.matches(l.path, l.value):
+			text = fmt.Sprintf("%s%s%s", strings.Repeat("  ", l.depth), marker, inspectorMatchStyle.Render(fmt.Sprintf("%s = %s", l.key, value)))
+		}
+		lines = append(lines, text)
+	}
+	sections = append(sections, padding.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)))
+
+	footer := helpStyle.Render(fmt.Sprintf("%d/%d", m.cursor+1, len(m.lines)))
+	if m.searching {
+		footer = m.search.View()
+	} else if m.status != "" {
+		footer = helpStyle.Render(m.status)
+	} else if m.search.Value() != "" {
+		footer = helpStyle.Render(fmt.Sprintf("%d/%d, search: %s", m.cursor+1, len(m.lines), m.search.Value()))
+	}
+	sections = append(sections, padding.Render(footer))
+	sections = append(sections, padding.Render(m.help.View(inspectorKeys)))
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
@@ -1,9 +1,72 @@
 package model
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// breadcrumbHeight is the number of lines MainModel uses above the screens.
+const breadcrumbHeight = 1
+
+var (
+	breadcrumbStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	activeBreadcrumbStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Bold(true)
 )
 
+type NavigationKeyMap struct {
+	Back    key.Binding
+	Forward key.Binding
+	Palette key.Binding
+	Quit    key.Binding
+}
+
+// navigationKeys work on every screen, they're handled by MainModel before
+// the active screen sees them.
+var navigationKeys = NavigationKeyMap{
+	Back: key.NewBinding(
+		key.WithKeys("alt+left"),
+		key.WithHelp("alt+←", "back"),
+	),
+	Forward: key.NewBinding(
+		key.WithKeys("alt+right"),
+		key.WithHelp("alt+→", "forward"),
+	),
+	Palette: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("ctrl+p", "command palette"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("ctrl+c", "quit"),
+	),
+}
+
+// backMsg asks MainModel to return to the previous screen. Screens send it
+// when q or esc is pressed, the unit table quits instead as it's always the
+// first screen.
+type backMsg struct{}
+
+func back() tea.Msg {
+	return backMsg{}
+}
+
+// titled is implemented by screens to name them in the breadcrumbs.
+type titled interface {
+	Title() string
+}
+
+// wheelScrolled is implemented by screens that scroll with the mouse wheel.
+// The mouse is only captured while they're active, capturing it keeps the
+// terminal from selecting text.
+type wheelScrolled interface {
+	wheelScrolled()
+}
+
 func NewMainModel() MainModel {
 	m := MainModel{}
 	t := NewTableModel(&m)
@@ -17,18 +80,157 @@ type MainModel struct {
 	UnitModel  *Unit
 
 	activeModel tea.Model
+	// history holds the screens visited before the active one, forward the
+	// screens left by going back
+	history []tea.Model
+	forward []tea.Model
+	// palette is shown over the active model while it's open
+	palette *Palette
+	// status is the result of the last palette command
+	status string
+	// size is the last window size without the breadcrumbs, screens miss it
+	// while they aren't active
+	size tea.WindowSizeMsg
 }
 
 func (m MainModel) Init() tea.Cmd {
 	return nil
 }
 
+// activate makes next the active screen, replays the window size to it and
+// captures the mouse when it scrolls with the wheel.
+func (m *MainModel) activate(next tea.Model) tea.Cmd {
+	m.activeModel = next
+	mouse := tea.DisableMouse
+	if _, ok := next.(wheelScrolled); ok {
+		mouse = tea.EnableMouseCellMotion
+	}
+	if m.size.Width == 0 {
+		return mouse
+	}
+	var cmd tea.Cmd
+	m.activeModel, cmd = next.Update(m.size)
+	return tea.Batch(cmd, mouse)
+}
+
+// navigate makes next the active screen. Screens that are already in the
+// history are returned to instead of visited again.
+func (m *MainModel) navigate(next tea.Model) tea.Cmd {
+	if next == nil || next == m.activeModel {
+		return nil
+	}
+	for i, previous := range m.history {
+		if previous == next {
+			m.history = m.history[:i]
+			m.forward = nil
+			return m.activate(next)
+		}
+	}
+	m.history = append(m.history, m.activeModel)
+	m.forward = nil
+	return m.activate(next)
+}
+
+func (m *MainModel) goBack() tea.Cmd {
+	if len(m.history) == 0 {
+		return nil
+	}
+	m.forward = append(m.forward, m.activeModel)
+	next := m.history[len(m.history)-1]
+	m.history = m.history[:len(m.history)-1]
+	return m.activate(next)
+}
+
+func (m *MainModel) goForward() tea.Cmd {
+	if len(m.forward) == 0 {
+		return nil
+	}
+	m.history = append(m.history, m.activeModel)
+	next := m.forward[len(m.forward)-1]
+	m.forward = m.forward[:len(m.forward)-1]
+	return m.activate(next)
+}
+
 func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
-	m.activeModel, cmd = m.activeModel.Update(msg)
-	return m, cmd
+	switch msg := msg.(type) {
+	case backMsg:
+		return m, m.goBack()
+	case tweaksLoadedMsg:
+		m.TableModel.ReloadRows()
+		m.status = fmt.Sprintf("Loaded tweaks, %d units changed", msg.changed)
+		return m, m.navigate(m.TableModel)
+	case tea.WindowSizeMsg:
+		msg.Height -= breadcrumbHeight
+		m.size = msg
+		m.activeModel, cmd = m.activeModel.Update(msg)
+		return m, cmd
+	case tea.KeyMsg:
+		m.status = ""
+		switch {
+		case key.Matches(msg, navigationKeys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, navigationKeys.Back):
+			return m, m.goBack()
+		case key.Matches(msg, navigationKeys.Forward):
+			return m, m.goForward()
+		case m.palette == nil && key.Matches(msg, navigationKeys.Palette):
+			m.palette = NewPalette(&m)
+			return m, textinput.Blink
+		}
+		if m.palette != nil {
+			entry, closed, cmd := m.palette.Update(msg)
+			if closed {
+				m.palette = nil
+			}
+			if entry != nil {
+				next, runCmd := entry.run(&m)
+				cmd = tea.Batch(cmd, runCmd, m.navigate(next))
+			}
+			return m, cmd
+		}
+	default:
+		if m.palette != nil {
+			// Keep the cursor of the palette blinking
+			var paletteCmd tea.Cmd
+			_, _, paletteCmd = m.palette.Update(msg)
+			var next tea.Model
+			next, cmd = m.activeModel.Update(msg)
+			return m, tea.Batch(cmd, paletteCmd, m.navigate(next))
+		}
+	}
+	var next tea.Model
+	next, cmd = m.activeModel.Update(msg)
+	return m, tea.Batch(cmd, m.navigate(next))
+}
+
+// breadcrumbs names the visited screens up to the active one.
+func (m MainModel) breadcrumbs() string {
+	title := func(model tea.Model) string {
+		if t, ok := model.(titled); ok {
+			return t.Title()
+		}
+		return "?"
+	}
+	crumbs := make([]string, 0, len(m.history)+1)
+	for _, h := range m.history {
+		crumbs = append(crumbs, breadcrumbStyle.Render(title(h)))
+	}
+	crumbs = append(crumbs, activeBreadcrumbStyle.Render(title(m.activeModel)))
+	line := strings.Join(crumbs, breadcrumbStyle.Render(" › "))
+	if len(m.forward) > 0 {
+		line += breadcrumbStyle.Render(" ›…")
+	}
+	return line
 }
 
 func (m MainModel) View() string {
-	return m.activeModel.View()
+	view := lipgloss.JoinVertical(lipgloss.Left, m.breadcrumbs(), m.activeModel.View())
+	if m.status != "" {
+		view = lipgloss.JoinVertical(lipgloss.Left, view, helpStyle.Render(m.status))
+	}
+	if m.palette != nil {
+		view = overlay(view, m.palette.View())
+	}
+	return view
 }
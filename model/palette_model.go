@@ -0,0 +1,278 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/util"
+)
+
+const (
+	paletteWidth   = 70
+	paletteResults = 10
+	exportFile     = "units.csv"
+)
+
+var (
+	paletteStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("57")).
+			Padding(0, 1).
+			Width(paletteWidth)
+	paletteCursorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	paletteDetailStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+)
+
+type PaletteKeyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Execute key.Binding
+	Close   key.Binding
+}
+
+var paletteKeys = PaletteKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "ctrl+k"),
+		key.WithHelp("↑", "previous"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "ctrl+j", "ctrl+n"),
+		key.WithHelp("↓", "next"),
+	),
+	Execute: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("<enter>", "run"),
+	),
+	Close: key.NewBinding(
+		key.WithKeys("esc", "ctrl+p"),
+		key.WithHelp("<esc>", "close"),
+	),
+}
+
+// paletteEntry is a unit or command in the palette. Run returns the model to
+// show next, or nil to stay on the current one.
+type paletteEntry struct {
+	title  string
+	detail string
+	// keywords are the texts the query is matched against
+	keywords []string
+	run      func(m *MainModel) (tea.Model, tea.Cmd)
+}
+
+// paletteCommands returns the commands available from every screen.
+func paletteCommands(m *MainModel) []paletteEntry {
+	t := m.TableModel
+	commands := []paletteEntry{
+		{
+			title: "Go to unit table",
+			run: func(m *MainModel) (tea.Model, tea.Cmd) {
+				return m.TableModel, nil
+			},
+		},
+		{
+			title: "Open grid parity report",
+			run: func(m *MainModel) (tea.Model, tea.Cmd) {
+				return NewParityModel(m.TableModel.mainModel), nil
+			},
+		},
+		{
+			title: "Search all units",
+			run: func(m *MainModel) (tea.Model, tea.Cmd) {
+				s := NewSearchModel(m.TableModel.mainModel, m.TableModel.IncludePvE)
+				return s, s.Init()
+			},
+		},
+		{
+			title:  "Compare selected units",
+			detail: fmt.Sprintf("%d selected", len(t.selectedRows)),
+			run: func(m *MainModel) (tea.Model, tea.Cmd) {
+				if len(m.TableModel.selectedRows) < 2 {
+					m.status = "Select at least two units in the unit table with <space> to compare them"
+					return nil, nil
+				}
+				return NewCompareModel(m.TableModel.mainModel, m.TableModel.selectedRows...), nil
+			},
+		},
+		{
+			title: "Load tweaks",
+			run: func(m *MainModel) (tea.Model, tea.Cmd) {
+				t := NewTweaksModel()
+				return t, t.Init()
+			},
+		},
+		{
+			title:  "Export unit table",
+			detail: exportFile,
+			run: func(m *MainModel) (tea.Model, tea.Cmd) {
+				if err := m.TableModel.Export(exportFile); err != nil {
+					m.status = fmt.Sprintf("Export failed: %s", err)
+					return nil, nil
+				}
+				m.status = fmt.Sprintf("Exported %d units to %s", len(m.TableModel.Table.Rows()), exportFile)
+				return nil, nil
+			},
+		},
+	}
+	for _, lang := range gamedata.Languages() {
+		if lang == gamedata.GetLanguage() {
+			continue
+		}
+		commands = append(commands, paletteEntry{
+			title: fmt.Sprintf("Switch language to %s", lang),
+			run: func(m *MainModel) (tea.Model, tea.Cmd) {
+				util.SetLanguage(lang)
+				m.TableModel.ReloadRows()
+				m.status = fmt.Sprintf("Language switched to %s", lang)
+				return nil, nil
+			},
+		})
+	}
+	for i := range commands {
+		commands[i].keywords = []string{commands[i].title}
+	}
+	return commands
+}
+
+// paletteUnits returns an entry opening the unit view for every unit in the
+// unit table.
+func paletteUnits(m *MainModel) []paletteEntry {
+	units := make([]paletteEntry, 0)
+	for _, ref := range util.BuildableUnits(m.TableModel.IncludePvE) {
+		detail := fmt.Sprintf("%s · %s", ref, util.FactionForRef(ref))
+		aliases := util.AliasesForRef(ref)
+		if len(aliases) > 0 {
+			detail = fmt.Sprintf("%s · %s", detail, strings.Join(aliases, ", "))
+		}
+		units = append(units, paletteEntry{
+			title:    util.NameForRef(ref),
+			detail:   detail,
+			keywords: append([]string{ref, util.NameForRef(ref)}, aliases...),
+			run: func(m *MainModel) (tea.Model, tea.Cmd) {
+				return NewUnitModel(ref, m.TableModel.mainModel, nil), nil
+			},
+		})
+	}
+	return units
+}
+
+func NewPalette(m *MainModel) *Palette {
+	ti := textinput.New()
+	ti.Prompt = "> "
+	ti.Placeholder = "unit, alias or command"
+	ti.CharLimit = 64
+	ti.Width = paletteWidth - 4
+	ti.Focus()
+
+	p := &Palette{
+		input:   ti,
+		entries: append(paletteCommands(m), paletteUnits(m)...),
+	}
+	p.filter()
+	return p
+}
+
+// Palette is an overlay to jump to units and run commands by fuzzy matching
+// their names.
+type Palette struct {
+	input   textinput.Model
+	entries []paletteEntry
+	results []paletteEntry
+	cursor  int
+}
+
+// filter ranks the entries matching the query, commands come first for an
+// empty query.
+func (p *Palette) filter() {
+	query := strings.TrimSpace(p.input.Value())
+	type scored struct {
+		entry paletteEntry
+		score int
+	}
+	matches := make([]scored, 0)
+	for _, e := range p.entries {
+		best, found := 0, false
+		for _, k := range e.keywords {
+			if score, ok := util.FuzzyMatch(query, k); ok && (!found || score > best) {
+				best, found = score, true
+			}
+		}
+		if found {
+			matches = append(matches, scored{e, best})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	p.results = make([]paletteEntry, len(matches))
+	for i, s := range matches {
+		p.results[i] = s.entry
+	}
+	p.cursor = 0
+}
+
+// Update handles a key, it returns the entry to run once one is chosen and
+// whether the palette should close.
+func (p *Palette) Update(msg tea.Msg) (*paletteEntry, bool, tea.Cmd) {
+	var cmd tea.Cmd
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, paletteKeys.Close):
+			return nil, true, cmd
+		case key.Matches(msg, paletteKeys.Up):
+			p.cursor = max(0, p.cursor-1)
+			return nil, false, cmd
+		case key.Matches(msg, paletteKeys.Down):
+			p.cursor = max(0, min(p.cursor+1, len(p.results)-1))
+			return nil, false, cmd
+		case key.Matches(msg, paletteKeys.Execute):
+			if p.cursor < len(p.results) {
+				return &p.results[p.cursor], true, cmd
+			}
+			return nil, false, cmd
+		}
+	}
+
+	previous := p.input.Value()
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != previous {
+		p.filter()
+	}
+	return nil, false, cmd
+}
+
+func (p *Palette) View() string {
+	lines := []string{p.input.View()}
+	offset := max(0, p.cursor-paletteResults+1)
+	for i := offset; i < min(len(p.results), offset+paletteResults); i++ {
+		e := p.results[i]
+		text := lipgloss.NewStyle().MaxWidth(paletteWidth - 2).Render(fmt.Sprintf("%s  %s", e.title, paletteDetailStyle.Render(e.detail)))
+		if i == p.cursor {
+			text = paletteCursorStyle.Width(paletteWidth - 2).MaxWidth(paletteWidth - 2).Render(fmt.Sprintf("%s  %s", e.title, e.detail))
+		}
+		lines = append(lines, text)
+	}
+	if len(p.results) == 0 {
+		lines = append(lines, paletteDetailStyle.Render("No matches"))
+	}
+	return paletteStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// overlay draws top over the first lines of base.
+func overlay(base string, top string) string {
+	baseLines := strings.Split(base, "\n")
+	topLines := strings.Split(top, "\n")
+	for i, line := range topLines {
+		if i+1 < len(baseLines) {
+			baseLines[i+1] = "  " + line
+		} else {
+			baseLines = append(baseLines, "  "+line)
+		}
+	}
+	return strings.Join(baseLines, "\n")
+}
@@ -0,0 +1,179 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/wezzle/bar-unit-info/bubbles/table"
+	"github.com/wezzle/bar-unit-info/util"
+)
+
+type ParityKeyMap struct {
+	table.KeyMap
+	Detail key.Binding
+	Help   key.Binding
+	Quit   key.Binding
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view. It's part
+// of the key.Map interface.
+func (k ParityKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.LineUp, k.LineDown, k.Detail, k.Help, k.Quit}
+}
+
+// FullHelp returns keybindings for the expanded help view. It's part of the
+// key.Map interface.
+func (k ParityKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.LineUp, k.LineDown, k.Detail, k.Help, k.Quit},
+		{k.GotoTop, k.GotoBottom, k.PageUp, k.PageDown, k.HalfPageUp, k.HalfPageDown},
+	}
+}
+
+var parityKeys = ParityKeyMap{
+	KeyMap: table.DefaultKeyMap(),
+	Detail: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("<enter>", "compare slot"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "esc"),
+		key.WithHelp("q/esc", "back"),
+	),
+}
+
+var parityColumnWidths = []int{12, 8, 12, 24, 7, 7, 7, 7, 7, 7, 6, 7, 6, 7, 6, 7, 14}
+
+func NewParityModel(mainModel *MainModel) *Parity {
+	report := util.Parity()
+
+	columns := make([]table.Column, 0)
+	tableWidth := 2
+	for i, title := range util.ParityHeader {
+		columns = append(columns, table.Column{Title: title, Width: parityColumnWidths[i]})
+		tableWidth = tableWidth + parityColumnWidths[i] + 2
+	}
+
+	rows := make([]table.Row, 0)
+	for _, r := range report.Records() {
+		rows = append(rows, table.Row(r))
+	}
+	slots := make([]util.ParitySlot, 0)
+	for _, slot := range report {
+		for range slot.Entries {
+			slots = append(slots, slot)
+		}
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(20),
+		table.WithKeyMap(parityKeys.KeyMap),
+	)
+
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(false)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+	t.SetStyles(s)
+
+	return &Parity{
+		Table:      t,
+		mainModel:  mainModel,
+		help:       help.New(),
+		tableWidth: tableWidth,
+		slots:      slots,
+	}
+}
+
+type Parity struct {
+	Table table.Model
+
+	mainModel  *MainModel
+	help       help.Model
+	tableWidth int
+	height     int
+	// slots holds the parity slot for every row in the table
+	slots []util.ParitySlot
+}
+
+func (m *Parity) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Parity) Title() string {
+	return "Grid parity"
+}
+
+func (m *Parity) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.resize(msg.Height)
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, parityKeys.Help):
+			m.help.ShowAll = !m.help.ShowAll
+			if m.height > 0 {
+				m.resize(m.height)
+			}
+		case key.Matches(msg, parityKeys.Quit):
+			return m, back
+		case key.Matches(msg, parityKeys.Detail):
+			cursor := m.Table.Cursor()
+			if cursor < 0 || cursor >= len(m.slots) {
+				break
+			}
+			refs := make([]string, 0)
+			for _, e := range m.slots[cursor].Entries {
+				if e.Ref != "" {
+					refs = append(refs, e.Ref)
+				}
+			}
+			if len(refs) == 1 {
+				return NewUnitModel(refs[0], m.mainModel, nil), cmd
+			}
+			return NewCompareModel(m.mainModel, refs...), cmd
+		}
+	}
+	m.Table, cmd = m.Table.Update(msg)
+	return m, cmd
+}
+
+// resize fits the table in height, below it are the status bar and the help.
+func (m *Parity) resize(height int) {
+	m.height = height
+	// The border takes two lines and a blank line separates the help
+	m.Table.SetHeight(max(5, height-2-lipgloss.Height(m.footer())))
+}
+
+func (m *Parity) footer() string {
+	doc := strings.Builder{}
+	doc.WriteString(statusBarStyle.Width(m.tableWidth).Render(statusText.Render("Faction parity, deltas relative to the first faction in each slot")))
+	doc.WriteString("\n\n")
+	doc.WriteString(m.help.View(parityKeys))
+	return doc.String()
+}
+
+func (m *Parity) View() string {
+	doc := strings.Builder{}
+	doc.WriteString(baseStyle.Render(m.Table.View()))
+	doc.WriteString("\n")
+	doc.WriteString(m.footer())
+	return doc.String()
+}
@@ -0,0 +1,362 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+	"github.com/wezzle/bar-unit-info/util"
+)
+
+var (
+	sandboxFocusedLabelStyle = lipgloss.NewStyle().Margin(0, 1, 0, 0).Foreground(lipgloss.Color("229"))
+	sandboxTweakStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+)
+
+type SandboxKeyMap struct {
+	Next   key.Binding
+	Prev   key.Binding
+	Reset  key.Binding
+	Output key.Binding
+	Help   key.Binding
+	Quit   key.Binding
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view. It's part
+// of the key.Map interface.
+func (k SandboxKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Next, k.Prev, k.Reset, k.Output, k.Help, k.Quit}
+}
+
+// FullHelp returns keybindings for the expanded help view. It's part of the
+// key.Map interface.
+func (k SandboxKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Next, k.Prev, k.Reset, k.Output, k.Help, k.Quit},
+	}
+}
+
+var sandboxKeys = SandboxKeyMap{
+	Next: key.NewBinding(
+		key.WithKeys("down", "tab"),
+		key.WithHelp("↓/tab", "next value"),
+	),
+	Prev: key.NewBinding(
+		key.WithKeys("up", "shift+tab"),
+		key.WithHelp("↑/shift+tab", "previous value"),
+	),
+	Reset: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "reset value"),
+	),
+	Output: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "print tweakunits"),
+	),
+	Help: key.NewBinding(
+		// ctrl+h is backspace in most terminals, the values are numbers so ? is
+		// never typed
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("<esc>", "back"),
+	),
+}
+
+// sandboxField is a single editable unit definition value.
+type sandboxField struct {
+	label string
+	path  []string
+	get   func(up *types.UnitProperties) float64
+	set   func(up *types.UnitProperties, v float64)
+	input textinput.Model
+}
+
+func sandboxFields(up *types.UnitProperties) []sandboxField {
+	fields := []sandboxField{
+		{
+			label: "Metal cost",
+			path:  []string{"metalcost"},
+			get:   func(up *types.UnitProperties) float64 { return float64(up.MetalCost) },
+			set:   func(up *types.UnitProperties, v float64) { up.MetalCost = int64(v) },
+		},
+		{
+			label: "Energy cost",
+			path:  []string{"energycost"},
+			get:   func(up *types.UnitProperties) float64 { return float64(up.EnergyCost) },
+			set:   func(up *types.UnitProperties, v float64) { up.EnergyCost = int64(v) },
+		},
+		{
+			label: "Health",
+			path:  []string{"health"},
+			get:   func(up *types.UnitProperties) float64 { return float64(up.Health) },
+			set:   func(up *types.UnitProperties, v float64) { up.Health = int64(v) },
+		},
+		{
+			label: "Speed",
+			path:  []string{"speed"},
+			get:   func(up *types.UnitProperties) float64 { return up.Speed },
+			set:   func(up *types.UnitProperties, v float64) { up.Speed = v },
+		},
+	}
+
+	names := make([]string, 0)
+	for name := range up.WeaponDefs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		name := name
+		fields = append(fields,
+			sandboxField{
+				label: fmt.Sprintf("%s damage", name),
+				path:  []string{"weapondefs", name, "damage", "default"},
+				get:   func(up *types.UnitProperties) float64 { return up.WeaponDefs[name].Damage["default"] },
+				set:   func(up *types.UnitProperties, v float64) { up.WeaponDefs[name].Damage["default"] = v },
+			},
+			sandboxField{
+				label: fmt.Sprintf("%s reload", name),
+				path:  []string{"weapondefs", name, "reloadtime"},
+				get:   func(up *types.UnitProperties) float64 { return up.WeaponDefs[name].ReloadTime },
+				set: func(up *types.UnitProperties, v float64) {
+					wd := up.WeaponDefs[name]
+					wd.ReloadTime = v
+					up.WeaponDefs[name] = wd
+				},
+			},
+			sandboxField{
+				label: fmt.Sprintf("%s range", name),
+				path:  []string{"weapondefs", name, "range"},
+				get:   func(up *types.UnitProperties) float64 { return up.WeaponDefs[name].Range },
+				set: func(up *types.UnitProperties, v float64) {
+					wd := up.WeaponDefs[name]
+					wd.Range = v
+					up.WeaponDefs[name] = wd
+				},
+			},
+		)
+	}
+
+	for i := range fields {
+		ti := textinput.New()
+		ti.CharLimit = 12
+		ti.Width = 12
+		ti.Prompt = ""
+		ti.SetValue(formatSandboxValue(fields[i].get(up)))
+		fields[i].input = ti
+	}
+	return fields
+}
+
+func formatSandboxValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func NewSandboxModel(ref types.UnitRef, mainModel *MainModel) *Sandbox {
+	stock, ok := gamedata.GetUnitPropertiesByRef(ref)
+	if !ok {
+		panic("unit properties file not generated")
+	}
+
+	m := &Sandbox{
+		ref:       ref,
+		name:      util.NameForRef(ref),
+		stock:     stock,
+		edited:    stock.Clone(),
+		fields:    sandboxFields(stock),
+		mainModel: mainModel,
+		help:      help.New(),
+	}
+	for _, r := range util.Counterparts(ref) {
+		if up, ok := gamedata.GetUnitPropertiesByRef(r); ok {
+			m.counterparts = append(m.counterparts, up)
+		}
+	}
+	m.fields[0].input.Focus()
+	return m
+}
+
+type Sandbox struct {
+	ref          types.UnitRef
+	name         string
+	stock        *types.UnitProperties
+	edited       *types.UnitProperties
+	counterparts []*types.UnitProperties
+	fields       []sandboxField
+	focused      int
+
+	mainModel *MainModel
+	help      help.Model
+}
+
+func (m *Sandbox) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Sandbox) focus(i int) {
+	m.fields[m.focused].input.Blur()
+	m.focused = (i + len(m.fields)) % len(m.fields)
+	m.fields[m.focused].input.Focus()
+}
+
+// apply recomputes the edited properties from the stock values and every
+// valid input.
+func (m *Sandbox) apply() {
+	m.edited = m.stock.Clone()
+	for _, f := range m.fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f.input.Value()), 64)
+		if err != nil {
+			continue
+		}
+		f.set(m.edited, v)
+	}
+}
+
+// Tweaks returns the values that differ from the stock unit definition.
+func (m *Sandbox) Tweaks() []util.TweakValue {
+	tweaks := make([]util.TweakValue, 0)
+	for _, f := range m.fields {
+		if f.get(m.edited) != f.get(m.stock) {
+			tweaks = append(tweaks, util.TweakValue{Path: f.path, Value: f.get(m.edited)})
+		}
+	}
+	return tweaks
+}
+
+func (m *Sandbox) EncodedTweaks() string {
+	tweaks := m.Tweaks()
+	if len(tweaks) == 0 {
+		return ""
+	}
+	return util.EncodeTweakUnits(map[types.UnitRef][]util.TweakValue{m.ref: tweaks})
+}
+
+func (m *Sandbox) Title() string {
+	return "Sandbox"
+}
+
+func (m *Sandbox) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, sandboxKeys.Quit):
+			return m, back
+		case key.Matches(msg, sandboxKeys.Help):
+			m.help.ShowAll = !m.help.ShowAll
+			return m, cmd
+		case key.Matches(msg, sandboxKeys.Next):
+			m.focus(m.focused + 1)
+			return m, cmd
+		case key.Matches(msg, sandboxKeys.Prev):
+			m.focus(m.focused - 1)
+			return m, cmd
+		case key.Matches(msg, sandboxKeys.Reset):
+			m.fields[m.focused].input.SetValue(formatSandboxValue(m.fields[m.focused].get(m.stock)))
+			m.apply()
+			return m, cmd
+		case key.Matches(msg, sandboxKeys.Output):
+			if encoded := m.EncodedTweaks(); encoded != "" {
+				return m, tea.Println(fmt.Sprintf("tweakunits=%s", encoded))
+			}
+			return m, cmd
+		}
+	}
+
+	m.fields[m.focused].input, cmd = m.fields[m.focused].input.Update(msg)
+	m.apply()
+	return m, cmd
+}
+
+func (m *Sandbox) View() string {
+	var sections []string
+
+	sections = append(sections, lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().
+			Background(lipgloss.Color("57")).
+			Foreground(lipgloss.Color("230")).
+			Padding(0, 1).
+			Margin(0, 4, 0, 0).
+			Render(fmt.Sprintf("Sandbox: %s", m.name)),
+		lipgloss.NewStyle().
+			Background(lipgloss.Color("236")).
+			Foreground(lipgloss.Color("246")).
+			Padding(0, 1).
+			Render(m.ref),
+	))
+
+	labelWidth := 0
+	for _, f := range m.fields {
+		labelWidth = max(labelWidth, lipgloss.Width(f.label)+1)
+	}
+	fieldLines := make([]string, 0)
+	for i, f := range m.fields {
+		ls := labelStyle
+		if i == m.focused {
+			ls = sandboxFocusedLabelStyle
+		}
+		stock := formatSandboxValue(f.get(m.stock))
+		line := lipgloss.JoinHorizontal(lipgloss.Top,
+			ls.Width(labelWidth+1).Render(fmt.Sprintf("%s:", f.label)),
+			lipgloss.NewStyle().Width(14).Render(f.input.View()),
+		)
+		if f.get(m.edited) != f.get(m.stock) {
+			line = line + sandboxTweakStyle.Render(fmt.Sprintf("stock %s", stock))
+		}
+		fieldLines = append(fieldLines, line)
+	}
+	sections = append(sections, padding.Render(lipgloss.JoinVertical(lipgloss.Left, fieldLines...)))
+
+	dps, healthPerMetal := util.CostEfficiency(m.edited)
+	stockDps, stockHealthPerMetal := util.CostEfficiency(m.stock)
+	results := []string{
+		fmt.Sprintf("%s %s", labelStyle.Render("DPS:"), sandboxComparison(m.edited.DPS(), m.stock.DPS())),
+		fmt.Sprintf("%s %s", labelStyle.Render("DPS per 100 metal:"), sandboxComparison(dps, stockDps)),
+		fmt.Sprintf("%s %s", labelStyle.Render("Health per 100 metal:"), sandboxComparison(healthPerMetal, stockHealthPerMetal)),
+	}
+	sections = append(sections, padding.Render(lipgloss.JoinVertical(lipgloss.Left, results...)))
+
+	if len(m.counterparts) > 0 {
+		matchups := []string{labelStyle.Render("Matchups (seconds to kill):")}
+		for _, c := range m.counterparts {
+			matchups = append(matchups, fmt.Sprintf("%s %s kills in %s, dies in %s",
+				labelStyle.Render(fmt.Sprintf("vs %s:", util.NameForRef(c.Ref))),
+				m.name,
+				sandboxComparison(util.TimeToKill(m.edited, c), util.TimeToKill(m.stock, c)),
+				sandboxComparison(util.TimeToKill(c, m.edited), util.TimeToKill(c, m.stock)),
+			))
+		}
+		sections = append(sections, padding.Render(lipgloss.JoinVertical(lipgloss.Left, matchups...)))
+	}
+
+	if encoded := m.EncodedTweaks(); encoded != "" {
+		sections = append(sections, padding.Render(lipgloss.JoinVertical(lipgloss.Left,
+			labelStyle.Render("tweakunits:"),
+			encoded,
+		)))
+	}
+
+	sections = append(sections, padding.Render(m.help.View(sandboxKeys)))
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+// sandboxComparison formats value and, when it changed, the stock value it is
+// compared to.
+func sandboxComparison(value float64, stock float64) string {
+	v := strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64)
+	if value == stock {
+		return v
+	}
+	return fmt.Sprintf("%s %s", sandboxTweakStyle.Render(v), helpStyle.Render(fmt.Sprintf("(%s)", util.FormatDelta(value, stock))))
+}
@@ -0,0 +1,176 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/wezzle/bar-unit-info/util"
+)
+
+var (
+	searchCursorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	searchMatchStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("6"))
+	searchFieldStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+)
+
+type SearchKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Detail key.Binding
+	Quit   key.Binding
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view. It's part
+// of the key.Map interface.
+func (k SearchKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Detail, k.Quit}
+}
+
+// FullHelp returns keybindings for the expanded help view. It's part of the
+// key.Map interface.
+func (k SearchKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Detail, k.Quit},
+	}
+}
+
+// The query takes every printable key, so the bindings avoid letters
+var searchKeys = SearchKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up"),
+		key.WithHelp("↑", "previous result"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down"),
+		key.WithHelp("↓", "next result"),
+	),
+	Detail: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("<enter>", "show unit detail"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("<esc>", "back"),
+	),
+}
+
+func NewSearchModel(mainModel *MainModel, includePvE bool) *Search {
+	ti := textinput.New()
+	ti.Prompt = "Search: "
+	ti.Placeholder = "ref, name, description, unit group or weapon"
+	ti.CharLimit = 64
+	ti.Width = 50
+	ti.Focus()
+
+	return &Search{
+		query:      ti,
+		includePvE: includePvE,
+		height:     20,
+		mainModel:  mainModel,
+		help:       help.New(),
+	}
+}
+
+// Search finds units across all factions by their texts while typing.
+type Search struct {
+	query      textinput.Model
+	includePvE bool
+	results    []util.SearchResult
+	cursor     int
+	offset     int
+	height     int
+
+	mainModel *MainModel
+	help      help.Model
+}
+
+func (m *Search) moveCursor(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.results)-1))
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+func (m *Search) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *Search) Title() string {
+	return "Search"
+}
+
+func (m *Search) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Every result takes two lines
+		m.height = max(3, (msg.Height-8)/2)
+		m.moveCursor(0)
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, searchKeys.Quit):
+			return m, back
+		case key.Matches(msg, searchKeys.Up):
+			m.moveCursor(-1)
+			return m, cmd
+		case key.Matches(msg, searchKeys.Down):
+			m.moveCursor(1)
+			return m, cmd
+		case key.Matches(msg, searchKeys.Detail):
+			if m.cursor < len(m.results) {
+				return NewUnitModel(m.results[m.cursor].Ref, m.mainModel, nil), cmd
+			}
+			return m, cmd
+		}
+	}
+
+	previous := m.query.Value()
+	m.query, cmd = m.query.Update(msg)
+	if m.query.Value() != previous {
+		m.results = util.SearchUnits(m.query.Value(), m.includePvE)
+		m.cursor = 0
+		m.offset = 0
+	}
+	return m, cmd
+}
+
+func (m *Search) View() string {
+	var sections []string
+	sections = append(sections, padding.Render(m.query.View()))
+
+	highlight := func(s string) string {
+		return searchMatchStyle.Render(s)
+	}
+	lines := make([]string, 0)
+	for i := m.offset; i < min(len(m.results), m.offset+m.height); i++ {
+		r := m.results[i]
+		title := fmt.Sprintf("%-12s %s  %s", r.Ref, util.NameForRef(r.Ref), searchFieldStyle.Render(util.FactionForRef(r.Ref)))
+		if i == m.cursor {
+			title = searchCursorStyle.Render(fmt.Sprintf("%-12s %s  %s", r.Ref, util.NameForRef(r.Ref), util.FactionForRef(r.Ref)))
+		}
+		matches := make([]string, 0)
+		for _, match := range r.Matches {
+			matches = append(matches, searchFieldStyle.Render(match.Field+": ")+util.HighlightTerms(match.Text, r.Terms, highlight))
+		}
+		lines = append(lines, title, "  "+strings.Join(matches, searchFieldStyle.Render(" · ")))
+	}
+	if len(lines) > 0 {
+		sections = append(sections, padding.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)))
+	}
+
+	status := fmt.Sprintf("%d results", len(m.results))
+	if len(m.results) > 0 {
+		status = fmt.Sprintf("%d/%d results", m.cursor+1, len(m.results))
+	}
+	sections = append(sections, padding.Render(helpStyle.Render(status)))
+	sections = append(sections, padding.Render(m.help.View(searchKeys)))
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
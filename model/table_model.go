@@ -1,13 +1,14 @@
 package model
 
 import (
+	"encoding/csv"
 	"fmt"
+	"os"
 	"regexp"
 	"slices"
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
@@ -80,6 +81,12 @@ const (
 	CTInt
 	CTInt64
 	CTFloat
+	// CTList columns hold several values, a filter of space or comma separated
+	// terms matches rows that have a value starting with every term.
+	CTList
+	// CTParams columns are filtered with util.MatchParams queries against the
+	// unit's customparams.
+	CTParams
 )
 
 func ValueForRowAndColumn(row table.Row, column ColumnWithType, columnIndex int) any {
@@ -96,6 +103,8 @@ type ColumnWithType struct {
 	table.Column
 	Type        ColumnType
 	PropertyKey string
+	// MatchAliases makes the filter also match the aliases of the unit
+	MatchAliases bool
 }
 
 func (c *ColumnWithType) ValueByPropertyKey(p *types.UnitProperties) any {
@@ -114,6 +123,10 @@ func (c *ColumnWithType) ValueByPropertyKey(p *types.UnitProperties) any {
 		return p.SightDistance
 	case "speed":
 		return p.Speed
+	case "wreckmetal":
+		return p.Wreck.Metal
+	case "heapmetal":
+		return p.Heap.Metal
 	}
 	return nil
 }
@@ -130,6 +143,15 @@ type TableKeyMap struct {
 	FilterCancel  key.Binding
 	ToggleSort    key.Binding
 	SelectRow     key.Binding
+	Parity        key.Binding
+	TogglePvE     key.Binding
+	ToggleTweaked key.Binding
+	LoadTweaks    key.Binding
+	Language      key.Binding
+	Search        key.Binding
+	Palette       key.Binding
+	Back          key.Binding
+	Forward       key.Binding
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view. It's part
@@ -144,6 +166,8 @@ func (k TableKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.LineUp, k.LineDown, k.Left, k.Right, k.ToggleSort, k.Detail, k.SelectRow, k.Help, k.Quit},
 		{k.GotoTop, k.GotoBottom, k.LineDown, k.PageDown, k.HalfPageUp, k.HalfPageDown},
+		{k.Palette, k.Search, k.Parity, k.TogglePvE, k.ToggleTweaked, k.LoadTweaks, k.Language},
+		{k.Back, k.Forward},
 	}
 }
 
@@ -170,7 +194,7 @@ var tableKeys = TableKeyMap{
 		key.WithHelp("?", "toggle help"),
 	),
 	Quit: key.NewBinding(
-		key.WithKeys("q", "ctrl+c"),
+		key.WithKeys("q"),
 		key.WithHelp("q", "quit"),
 	),
 	Filter: key.NewBinding(
@@ -193,13 +217,55 @@ var tableKeys = TableKeyMap{
 		key.WithKeys(spacebar),
 		key.WithHelp("<space>", "select row"),
 	),
+	Parity: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "faction parity report"),
+	),
+	TogglePvE: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "toggle PvE units"),
+	),
+	ToggleTweaked: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "toggle tweaked data"),
+	),
+	LoadTweaks: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "load tweaks"),
+	),
+	Language: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "switch language"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("ctrl+f"),
+		key.WithHelp("ctrl+f", "search all units"),
+	),
+	// Handled by MainModel, listed for the help view
+	Palette: navigationKeys.Palette,
+	Back:    navigationKeys.Back,
+	Forward: navigationKeys.Forward,
+}
+
+func unitRows(includePvE bool) ([]table.Row, types.UnitPropertiesByRef) {
+	properties := make(types.UnitPropertiesByRef)
+	rows := make([]table.Row, 0)
+	for _, ref := range util.BuildableUnits(includePvE) {
+		up, ok := gamedata.GetUnitPropertiesByRef(ref)
+		if !ok {
+			panic(ref)
+		}
+		properties[ref] = up
+		rows = append(rows, table.Row(util.UnitRecord(ref, up)))
+	}
+	return rows, properties
 }
 
 func NewTableModel(mainModel *MainModel) Table {
 	columns := []ColumnWithType{
-		{Column: table.Column{Title: "Ref ▼ •", Width: 20}, Type: CTString},
+		{Column: table.Column{Title: "Ref ▼ •", Width: 20}, Type: CTString, MatchAliases: true},
 		{Column: table.Column{Title: "Faction", Width: 20}, Type: CTString},
-		{Column: table.Column{Title: "Name", Width: 30}, Type: CTString},
+		{Column: table.Column{Title: "Name", Width: 30}, Type: CTString, MatchAliases: true},
 		{Column: table.Column{Title: "Tech level", Width: 15}, Type: CTInt, PropertyKey: "techlevel"},
 		{Column: table.Column{Title: "Metal cost", Width: 15}, Type: CTInt64, PropertyKey: "metalcost"},
 		{Column: table.Column{Title: "Energy cost", Width: 15}, Type: CTInt64, PropertyKey: "energycost"},
@@ -207,6 +273,12 @@ func NewTableModel(mainModel *MainModel) Table {
 		{Column: table.Column{Title: "Health", Width: 15}, Type: CTInt64, PropertyKey: "health"},
 		{Column: table.Column{Title: "Sight range", Width: 15}, Type: CTInt64, PropertyKey: "sightdistance"},
 		{Column: table.Column{Title: "Speed", Width: 15}, Type: CTFloat, PropertyKey: "speed"},
+		{Column: table.Column{Title: "Domain", Width: 10}, Type: CTString},
+		{Column: table.Column{Title: "Abilities", Width: 30}, Type: CTList},
+		{Column: table.Column{Title: "Wreck metal", Width: 15}, Type: CTFloat, PropertyKey: "wreckmetal"},
+		{Column: table.Column{Title: "Heap metal", Width: 15}, Type: CTFloat, PropertyKey: "heapmetal"},
+		{Column: table.Column{Title: "Source", Width: 12}, Type: CTString},
+		{Column: table.Column{Title: "Custom params", Width: 40}, Type: CTParams},
 	}
 
 	tableColumns := make([]table.Column, 0)
@@ -222,61 +294,7 @@ func NewTableModel(mainModel *MainModel) Table {
 	}
 	tableWidth = tableWidth + defaultBorderWidth*2
 
-	buildableUnits := make([]types.UnitRef, 0)
-	properties := make(types.UnitPropertiesByRef)
-	rows := make([]table.Row, 0)
-
-	// Use labs to find buildable units
-	for ref := range gamedata.GetLabGrid() {
-		up, ok := gamedata.GetUnitPropertiesByRef(ref)
-		if !ok {
-			continue
-		}
-		for _, boRef := range up.BuildOptions {
-			boUp, ok := gamedata.GetUnitPropertiesByRef(boRef)
-			if !ok {
-				continue
-			}
-			buildableUnits = append(buildableUnits, boRef)
-			properties[boRef] = boUp
-		}
-	}
-
-	// Check all buildable units for buildable units of their own
-	for _, ref := range buildableUnits {
-		up := properties[ref]
-		for _, boRef := range up.BuildOptions {
-			boUp, ok := gamedata.GetUnitPropertiesByRef(boRef)
-			if !ok {
-				continue
-			}
-			buildableUnits = append(buildableUnits, boRef)
-			properties[boRef] = boUp
-		}
-	}
-
-	sort.Strings(buildableUnits)
-	buildableUnits = util.RemoveDuplicate(buildableUnits)
-
-	for _, ref := range buildableUnits {
-		up, ok := properties[ref]
-		if !ok {
-			panic(ref)
-		}
-		d := time.Second * time.Duration(up.Buildtime/100)
-		rows = append(rows, table.Row{
-			ref,
-			util.FactionForRef(ref),
-			util.NameForRef(ref),
-			fmt.Sprintf("T%d", up.CustomParams.TechLevel),
-			strconv.FormatInt(up.MetalCost, 10),
-			strconv.FormatInt(up.EnergyCost, 10),
-			d.String(),
-			strconv.FormatInt(up.Health, 10),
-			strconv.FormatInt(up.SightDistance, 10),
-			strconv.FormatFloat(up.Speed, 'f', 1, 64),
-		})
-	}
+	rows, properties := unitRows(false)
 
 	t := table.New(
 		table.WithColumns(tableColumns),
@@ -336,6 +354,7 @@ type Table struct {
 	DialogShown bool
 	FilterMode  bool
 	SelectedCol int
+	IncludePvE  bool
 
 	mainModel  *MainModel
 	help       help.Model
@@ -365,6 +384,23 @@ func (m *Table) FilterRows(cf []string) {
 			case CTString:
 				re := regexp.MustCompile(fmt.Sprintf("(?i)%s", f))
 				found = re.Match([]byte(r[colIndex]))
+				if !found && m.columns[colIndex].MatchAliases {
+					found = slices.ContainsFunc(util.AliasesForRef(r[0]), re.MatchString)
+				}
+			case CTList:
+				values := strings.Split(strings.TrimSuffix(r[colIndex], util.TweakedMarker), util.AbilitySeparator)
+				found = true
+				for _, term := range strings.FieldsFunc(f, func(r rune) bool { return r == ',' || r == ' ' }) {
+					re, err := regexp.Compile(fmt.Sprintf("(?i)^%s", term))
+					if err != nil || !slices.ContainsFunc(values, re.MatchString) {
+						found = false
+						break
+					}
+				}
+			case CTParams:
+				if up, ok := gamedata.GetUnitPropertiesByRef(r[0]); ok {
+					found = util.MatchParams(up.CustomParams.Params, f)
+				}
 			case CTInt:
 				val := ValueForRowAndColumn(r, m.columns[colIndex], colIndex).(int)
 				cleanFilterString := string(regexp.MustCompile("[><= ]+").ReplaceAll([]byte(f), []byte("")))
@@ -408,6 +444,96 @@ func (m *Table) FilterRows(cf []string) {
 	m.Table.SetRows(filteredRows)
 }
 
+func (m *Table) SortRows() {
+	t := m.columns[m.SortCol].Type
+	rows := m.Table.Rows()
+	sort.Slice(rows, func(i, j int) bool {
+		iVal := ValueForRowAndColumn(rows[i], m.columns[m.SortCol], m.SortCol)
+		jVal := ValueForRowAndColumn(rows[j], m.columns[m.SortCol], m.SortCol)
+		var isLess bool
+		switch t {
+		case CTInt:
+			isLess = iVal.(int) < jVal.(int)
+		case CTInt64:
+			isLess = iVal.(int64) < jVal.(int64)
+		case CTFloat:
+			isLess = iVal.(float64) < jVal.(float64)
+		default:
+			isLess = strings.ToLower(iVal.(string)) < strings.ToLower(jVal.(string))
+		}
+		if m.Reverse {
+			return !isLess
+		}
+		return isLess
+	})
+	m.Table.SetRows(rows)
+	m.SetHighlightedRows()
+}
+
+// TogglePvE adds or removes PvE units from the table while keeping the
+// current filters and sorting.
+func (m *Table) TogglePvE() {
+	m.IncludePvE = !m.IncludePvE
+	m.ReloadRows()
+}
+
+// ToggleTweaked switches between the stock data and the data with tweakdefs
+// and tweakunits applied, when the data was generated with tweaks.
+func (m *Table) ToggleTweaked() {
+	if !gamedata.HasTweaks() {
+		return
+	}
+	gamedata.UseTweakedUnitProperties(!gamedata.IsUsingTweakedUnitProperties())
+	m.ReloadRows()
+}
+
+// NextLanguage switches the unit names and descriptions to the next language
+// the data was generated with.
+func (m *Table) NextLanguage() {
+	languages := gamedata.Languages()
+	if len(languages) < 2 {
+		return
+	}
+	next := languages[0]
+	for i, lang := range languages {
+		if lang == gamedata.GetLanguage() {
+			next = languages[(i+1)%len(languages)]
+		}
+	}
+	util.SetLanguage(next)
+	m.ReloadRows()
+}
+
+// Export writes the rows shown in the table to file as CSV.
+func (m *Table) Export(file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.Write(util.UnitHeader); err != nil {
+		return err
+	}
+	for _, r := range m.Table.Rows() {
+		if err := w.Write(util.UnmarkTweaked(r)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// ReloadRows rebuilds the rows from the unit data while keeping the current
+// filters and sorting.
+func (m *Table) ReloadRows() {
+	m.rows, m.unitPropertiesByRef = unitRows(m.IncludePvE)
+	m.FilterInput.SetValue(m.columnFilters[m.SelectedCol])
+	m.FilterRows(m.columnFilters)
+	m.SortRows()
+}
+
 func (m *Table) SetHighlightedRows() {
 	h := make([]int, 0)
 	for i, r := range m.Table.Rows() {
@@ -487,13 +613,30 @@ func (m *Table) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return NewCompareModel(m.mainModel, m.selectedRows...), cmd
 			}
 
-			// Find counterpart buildings for other factions by using grid menu index
-			if gamedata.UnitPropertiesByRef[selectedRef].IsBuilding() {
+			// Find counterparts for other factions by using grid menu or lab index
+			if counterparts := util.Counterparts(selectedRef); len(counterparts) > 0 {
 				refs := []types.UnitRef{selectedRef}
-				refs = append(refs, util.CounterpartForBuilding(selectedRef)...)
+				refs = append(refs, counterparts...)
 				return NewCompareModel(m.mainModel, refs...), cmd
 			}
 			return NewUnitModel(m.Table.SelectedRow()[0], m.mainModel, nil), cmd
+		case key.Matches(msg, tableKeys.Parity):
+			return NewParityModel(m.mainModel), cmd
+		case key.Matches(msg, tableKeys.Search):
+			s := NewSearchModel(m.mainModel, m.IncludePvE)
+			return s, s.Init()
+		case key.Matches(msg, tableKeys.TogglePvE):
+			m.TogglePvE()
+			preventPropagation = true
+		case key.Matches(msg, tableKeys.ToggleTweaked):
+			m.ToggleTweaked()
+			preventPropagation = true
+		case key.Matches(msg, tableKeys.LoadTweaks):
+			t := NewTweaksModel()
+			return t, t.Init()
+		case key.Matches(msg, tableKeys.Language):
+			m.NextLanguage()
+			preventPropagation = true
 		case key.Matches(msg, tableKeys.Left):
 			s := max(m.SelectedCol-1, 0)
 			selectedCol = &s
@@ -541,29 +684,7 @@ func (m *Table) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.SortCol = *sortCol
 			m.Reverse = reverse
 
-			t := m.columns[*sortCol].Type
-			rows := m.Table.Rows()
-			sort.Slice(rows, func(i, j int) bool {
-				iVal := ValueForRowAndColumn(rows[i], m.columns[*sortCol], *sortCol)
-				jVal := ValueForRowAndColumn(rows[j], m.columns[*sortCol], *sortCol)
-				var isLess bool
-				switch t {
-				case CTInt:
-					isLess = iVal.(int) < jVal.(int)
-				case CTInt64:
-					isLess = iVal.(int64) < jVal.(int64)
-				case CTFloat:
-					isLess = iVal.(float64) < jVal.(float64)
-				default:
-					isLess = strings.ToLower(iVal.(string)) < strings.ToLower(jVal.(string))
-				}
-				if reverse {
-					return !isLess
-				}
-				return isLess
-			})
-			m.Table.SetRows(rows)
-			m.SetHighlightedRows()
+			m.SortRows()
 		}
 
 		selectedColUpdate := selectedCol != nil && m.SelectedCol != *selectedCol
@@ -588,6 +709,27 @@ func (m *Table) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m *Table) Title() string {
+	return "Units"
+}
+
+func (m *Table) unitCount() string {
+	count := fmt.Sprintf("Unit count: %d", len(m.Table.Rows()))
+	if m.IncludePvE {
+		count = fmt.Sprintf("%s (incl. PvE)", count)
+	}
+	if gamedata.IsUsingTweakedUnitProperties() {
+		count = fmt.Sprintf("%s, tweaked values marked with %s", count, util.TweakedMarker)
+	}
+	if len(gamedata.Languages()) > 1 {
+		count = fmt.Sprintf("%s, language: %s", count, gamedata.GetLanguage())
+	}
+	if mo := util.ModOptionsSummary(); mo != "" {
+		count = fmt.Sprintf("%s, modoptions: %s", count, mo)
+	}
+	return count
+}
+
 func (m *Table) View() string {
 	// physicalWidth, _, _ := term.GetSize(int(os.Stdout.Fd()))
 
@@ -628,7 +770,7 @@ func (m *Table) View() string {
 		}
 		statusVal := statusText.
 			Width(m.tableWidth - w(fishCake)).
-			Render(fmt.Sprintf("Unit count: %d", len(m.Table.Rows())))
+			Render(m.unitCount())
 
 		bar := lipgloss.JoinHorizontal(lipgloss.Top,
 			statusVal,
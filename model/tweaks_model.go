@@ -0,0 +1,140 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+	"github.com/wezzle/bar-unit-info/util"
+)
+
+var tweaksErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+type TweaksKeyMap struct {
+	Load key.Binding
+	Quit key.Binding
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view. It's part
+// of the key.Map interface.
+func (k TweaksKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Load, k.Quit}
+}
+
+// FullHelp returns keybindings for the expanded help view. It's part of the
+// key.Map interface.
+func (k TweaksKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Load, k.Quit},
+	}
+}
+
+// The input takes every printable key, so the bindings avoid letters
+var tweaksKeys = TweaksKeyMap{
+	Load: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("<enter>", "load tweaks"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("<esc>", "back"),
+	),
+}
+
+func NewTweaksModel() *Tweaks {
+	ti := textinput.New()
+	ti.Prompt = "Tweaks: "
+	ti.Placeholder = "base64 tweakdefs or tweakunits, or a file"
+	ti.Width = 60
+	ti.Focus()
+
+	return &Tweaks{
+		input: ti,
+		help:  help.New(),
+	}
+}
+
+// Tweaks loads tweakdefs and tweakunits pasted from a lobby or read from a
+// file and switches the unit table to the tweaked data.
+type Tweaks struct {
+	input textinput.Model
+	err   error
+
+	help help.Model
+}
+
+// tweaksLoadedMsg asks MainModel to reload the unit table with the loaded
+// tweaks and return to it.
+type tweaksLoadedMsg struct {
+	changed int
+}
+
+func (m *Tweaks) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *Tweaks) Title() string {
+	return "Load tweaks"
+}
+
+// load reads the tweaks from the file named by the input or from the base64
+// strings in it.
+func (m *Tweaks) load() (int, error) {
+	value := strings.TrimSpace(m.input.Value())
+	var (
+		mo  types.ModOptions
+		err error
+	)
+	if _, statErr := os.Stat(value); statErr == nil {
+		mo, err = util.ReadTweaksFile(value)
+	} else {
+		mo, err = util.TweakModOptions(strings.Fields(value))
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(mo) == 0 {
+		return 0, fmt.Errorf("no tweaks found")
+	}
+	return util.LoadTweaks(mo)
+}
+
+func (m *Tweaks) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, tweaksKeys.Quit):
+			return m, back
+		case key.Matches(msg, tweaksKeys.Load):
+			changed, err := m.load()
+			if err != nil {
+				m.err = err
+				return m, cmd
+			}
+			gamedata.UseTweakedUnitProperties(true)
+			return m, func() tea.Msg {
+				return tweaksLoadedMsg{changed}
+			}
+		}
+	}
+
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *Tweaks) View() string {
+	sections := []string{padding.Render(m.input.View())}
+	if m.err != nil {
+		sections = append(sections, padding.Render(tweaksErrorStyle.Render(m.err.Error())))
+	}
+	sections = append(sections, padding.Render(helpStyle.Render("Tweaks are applied to the stock unit definitions and replace tweaks loaded before.")))
+	sections = append(sections, padding.Render(m.help.View(tweaksKeys)))
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
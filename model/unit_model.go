@@ -3,10 +3,14 @@ package model
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
@@ -27,19 +31,148 @@ var (
 				Align(lipgloss.Center, lipgloss.Center).
 				BorderStyle(lipgloss.NormalBorder()).
 				BorderForeground(lipgloss.Color("69"))
-	helpStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
-	labelStyle       = lipgloss.NewStyle().Margin(0, 1, 0, 0).Foreground(lipgloss.Color("241"))
-	descriptionStyle = lipgloss.NewStyle().Margin(1, 0, 0).Foreground(lipgloss.Color("245"))
-	padding          = lipgloss.NewStyle().Margin(1, 0, 0)
-	weaponStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#cc0000"))
-	factionColors    = map[string]string{
-		"Armada": "27",
-		"Cortex": "124",
-		"Legion": "34",
-	}
+	helpStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	labelStyle        = lipgloss.NewStyle().Margin(0, 1, 0, 0).Foreground(lipgloss.Color("241"))
+	descriptionStyle  = lipgloss.NewStyle().Margin(1, 0, 0).Foreground(lipgloss.Color("245"))
+	padding           = lipgloss.NewStyle().Margin(1, 0, 0)
+	weaponStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("#cc0000"))
+	tweakedStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	linkStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("69"))
+	selectedLinkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
 	defaultBaseValues = map[string]float64{}
+	badgeStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("230")).Padding(0, 1).Margin(0, 1, 0, 0)
+	abilityColors     = map[string]string{
+		"builder":       "94",
+		"reclaim":       "58",
+		"repair":        "28",
+		"resurrect":     "30",
+		"capture":       "127",
+		"cloak":         "61",
+		"stealth":       "60",
+		"sonar-stealth": "24",
+		"transport":     "130",
+		"on/off":        "238",
+	}
 )
 
+// linksWidth is the width the link sections of the unit view wrap at.
+const linksWidth = 80
+
+// Link sections of the unit view, in the order they're shown
+const (
+	linkSectionBuiltBy      = "Built by"
+	linkSectionBuilds       = "Builds"
+	linkSectionCounterparts = "Counterparts"
+)
+
+type UnitKeyMap struct {
+	viewport.KeyMap
+	GotoTop     key.Binding
+	GotoBottom  key.Binding
+	NextSection key.Binding
+	PrevSection key.Binding
+	NextLink    key.Binding
+	PrevLink    key.Binding
+	OpenLink    key.Binding
+	Sandbox     key.Binding
+	Inspector   key.Binding
+	Help        key.Binding
+	Quit        key.Binding
+	Palette     key.Binding
+	Back        key.Binding
+	Forward     key.Binding
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view. It's part
+// of the key.Map interface.
+func (k UnitKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.NextSection, k.NextLink, k.Help, k.Quit}
+}
+
+// FullHelp returns keybindings for the expanded help view. It's part of the
+// key.Map interface.
+func (k UnitKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.PageUp, k.PageDown, k.HalfPageUp, k.HalfPageDown, k.GotoTop, k.GotoBottom},
+		{k.NextSection, k.PrevSection, k.NextLink, k.PrevLink, k.OpenLink},
+		{k.Sandbox, k.Inspector, k.Help, k.Quit},
+		{k.Palette, k.Back, k.Forward},
+	}
+}
+
+var unitKeys = UnitKeyMap{
+	KeyMap: viewport.DefaultKeyMap(),
+	GotoTop: key.NewBinding(
+		key.WithKeys("home", "g"),
+		key.WithHelp("g/home", "go to start"),
+	),
+	GotoBottom: key.NewBinding(
+		key.WithKeys("end", "G"),
+		key.WithHelp("G/end", "go to end"),
+	),
+	NextSection: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next section"),
+	),
+	PrevSection: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "previous section"),
+	),
+	NextLink: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("<tab>", "next link"),
+	),
+	PrevLink: key.NewBinding(
+		key.WithKeys("shift+tab"),
+		key.WithHelp("<shift+tab>", "previous link"),
+	),
+	OpenLink: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("<enter>", "open link"),
+	),
+	Sandbox: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "balance sandbox"),
+	),
+	Inspector: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "inspect unit def"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "esc"),
+		key.WithHelp("q/esc", "back"),
+	),
+	// Handled by MainModel, listed for the help view
+	Palette: navigationKeys.Palette,
+	Back:    navigationKeys.Back,
+	Forward: navigationKeys.Forward,
+}
+
+// unitSection is a part of the unit view the section keys jump to, line is
+// where it starts in the content.
+type unitSection struct {
+	name string
+	line int
+}
+
+// linkSection is the rendered list of the linked units of one section, lines
+// holds the line within view of every link by its index in links.
+type linkSection struct {
+	name  string
+	view  string
+	lines map[int]int
+}
+
+// unitLink is a related unit shown in the unit view that can be opened.
+type unitLink struct {
+	section string
+	ref     types.UnitRef
+}
+
 type BaseValues struct {
 	MetalCost      float64
 	EnergyCost     float64
@@ -90,6 +223,19 @@ func NewUnitModel(ref types.UnitRef, mainModel *MainModel, baseValues *BaseValue
 	m.baseValues = baseValues
 
 	m.faction = util.FactionForRef(ref)
+	m.linkCursor = -1
+	for _, section := range []struct {
+		name string
+		refs []types.UnitRef
+	}{
+		{linkSectionBuiltBy, util.BuiltBy(ref)},
+		{linkSectionBuilds, util.Builds(ref)},
+		{linkSectionCounterparts, util.Counterparts(ref)},
+	} {
+		for _, r := range section.refs {
+			m.links = append(m.links, unitLink{section.name, r})
+		}
+	}
 	m.metalCost = progress.New(progress.WithSolidFill("#383C3F"), progress.WithoutPercentage())
 	m.energyCost = progress.New(progress.WithSolidFill("#9E6802"), progress.WithoutPercentage())
 	m.buildtime = progress.New(progress.WithSolidFill("#FEED53"), progress.WithoutPercentage())
@@ -107,6 +253,12 @@ func NewUnitModel(ref types.UnitRef, mainModel *MainModel, baseValues *BaseValue
 	m.weaponMps = progress.New(progress.WithSolidFill("#383C3F"), progress.WithoutPercentage())
 	m.weaponParalyzeTime = progress.New(progress.WithSolidFill("#1175AE"), progress.WithoutPercentage())
 
+	// MainModel sends the window size once the screen is active
+	m.help = help.New()
+	m.viewport = viewport.New(0, 0)
+	m.viewport.KeyMap = unitKeys.KeyMap
+	m.resize(0, 0)
+
 	return &m
 }
 
@@ -119,6 +271,21 @@ type Unit struct {
 
 	mainModel *MainModel
 
+	// links are the units built by, building and counterparting this one,
+	// linkCursor the selected link or -1
+	links      []unitLink
+	linkCursor int
+	// hideLinks leaves the link sections out, e.g. when comparing units
+	hideLinks bool
+	// linkLines holds the line of every link in the content
+	linkLines []int
+	sections  []unitSection
+
+	viewport viewport.Model
+	help     help.Model
+	width    int
+	height   int
+
 	// Stats
 	metalCost          progress.Model
 	energyCost         progress.Model
@@ -148,19 +315,175 @@ func (m *Unit) Init() tea.Cmd {
 	return nil
 }
 
+func (m *Unit) wheelScrolled() {}
+
+func (m *Unit) Title() string {
+	if m.name == "" {
+		return m.ref
+	}
+	return m.name
+}
+
 func (m *Unit) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.resize(msg.Width, msg.Height)
+		return m, cmd
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
-			return m.mainModel.TableModel, cmd
+		switch {
+		case key.Matches(msg, unitKeys.Quit):
+			return m, back
+		case key.Matches(msg, unitKeys.Sandbox):
+			return NewSandboxModel(m.ref, m.mainModel), cmd
+		case key.Matches(msg, unitKeys.Inspector):
+			return NewInspectorModel(m.ref, m.mainModel), cmd
+		case key.Matches(msg, unitKeys.Help):
+			m.help.ShowAll = !m.help.ShowAll
+			m.resize(m.width, m.height)
+			return m, cmd
+		case key.Matches(msg, unitKeys.GotoTop):
+			m.viewport.GotoTop()
+			return m, cmd
+		case key.Matches(msg, unitKeys.GotoBottom):
+			m.viewport.GotoBottom()
+			return m, cmd
+		case key.Matches(msg, unitKeys.NextSection):
+			m.jumpSection(1)
+			return m, cmd
+		case key.Matches(msg, unitKeys.PrevSection):
+			m.jumpSection(-1)
+			return m, cmd
+		case key.Matches(msg, unitKeys.NextLink):
+			m.moveLinkCursor(1)
+			return m, cmd
+		case key.Matches(msg, unitKeys.PrevLink):
+			m.moveLinkCursor(-1)
+			return m, cmd
+		case key.Matches(msg, unitKeys.OpenLink):
+			if m.linkCursor >= 0 && m.linkCursor < len(m.links) {
+				return NewUnitModel(m.links[m.linkCursor].ref, m.mainModel, nil), cmd
+			}
+			return m, cmd
 		}
 	}
 
+	// Handle scrolling keys and the mouse wheel in the viewport
+	m.viewport, cmd = m.viewport.Update(msg)
 	return m, cmd
 }
 
+// resize fits the viewport in width and height, leaving room for the footer.
+func (m *Unit) resize(width int, height int) {
+	m.width, m.height = width, height
+	m.help.Width = width
+	m.viewport.Width = width
+	m.viewport.Height = max(1, height-lipgloss.Height(m.footer()))
+	// The links wrap at the width
+	m.viewport.SetContent(m.renderContent())
+}
+
+// moveLinkCursor selects the next or previous link, wrapping around at either
+// end, and scrolls it into view.
+func (m *Unit) moveLinkCursor(delta int) {
+	if len(m.links) == 0 {
+		return
+	}
+	if m.linkCursor < 0 && delta < 0 {
+		m.linkCursor = 0
+	}
+	m.linkCursor = (m.linkCursor + delta + len(m.links)) % len(m.links)
+	m.viewport.SetContent(m.renderContent())
+
+	line := m.linkLines[m.linkCursor]
+	if line < m.viewport.YOffset {
+		m.viewport.SetYOffset(line)
+	} else if line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(line - m.viewport.Height + 1)
+	}
+}
+
+// jumpSection scrolls to the start of the next or previous section.
+func (m *Unit) jumpSection(delta int) {
+	if delta > 0 {
+		for _, section := range m.sections {
+			if section.line > m.viewport.YOffset {
+				m.viewport.SetYOffset(section.line)
+				return
+			}
+		}
+		return
+	}
+	for i := len(m.sections) - 1; i >= 0; i-- {
+		if m.sections[i].line < m.viewport.YOffset {
+			m.viewport.SetYOffset(m.sections[i].line)
+			return
+		}
+	}
+	m.viewport.GotoTop()
+}
+
+// currentSection names the section at the top of the viewport.
+func (m *Unit) currentSection() string {
+	name := "Overview"
+	for _, section := range m.sections {
+		if section.line > m.viewport.YOffset {
+			break
+		}
+		name = section.name
+	}
+	return name
+}
+
+func (m *Unit) footer() string {
+	status := fmt.Sprintf("%s · %3.f%%", m.currentSection(), m.viewport.ScrollPercent()*100)
+	return padding.Render(lipgloss.JoinVertical(lipgloss.Left,
+		helpStyle.Render(status),
+		m.help.View(unitKeys),
+	))
+}
+
+// linkSections renders a wrapped list of the linked units per section, with
+// the selected link highlighted.
+func (m *Unit) linkSections() []linkSection {
+	width := linksWidth
+	if m.width > 0 {
+		width = min(width, m.width)
+	}
+	sections := make([]linkSection, 0)
+	for i := 0; i < len(m.links); {
+		section := linkSection{name: m.links[i].section, lines: make(map[int]int)}
+		label := labelStyle.Render(section.name + ":")
+		lines := make([]string, 0)
+		line := label
+		for ; i < len(m.links) && m.links[i].section == section.name; i++ {
+			name := util.NameForRef(m.links[i].ref)
+			if name == "" {
+				name = m.links[i].ref
+			}
+			item := linkStyle.Render(name)
+			if i == m.linkCursor {
+				item = selectedLinkStyle.Render(name)
+			}
+			if line != "" && ansi.StringWidth(line)+ansi.StringWidth(name)+3 > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line == "" || line == label {
+				line += item
+			} else {
+				line += helpStyle.Render(" · ") + item
+			}
+			// The padding adds a line above the list
+			section.lines[i] = len(lines) + 1
+		}
+		lines = append(lines, line)
+		section.view = padding.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+		sections = append(sections, section)
+	}
+	return sections
+}
+
 func (m *Unit) RenderBar(labelWidth int, label string, progress string, maxValueWidth int, value string) string {
 	v := value
 	for range maxValueWidth - len(value) {
@@ -184,12 +507,74 @@ func (m *Unit) PercentageWithBaseF(value float64, base float64) float64 {
 	return min(value/base, 100.0) / 100
 }
 
+// abilityBadge renders an ability with the values that matter for it.
+func (m *Unit) abilityBadge(ability string) string {
+	a := m.properties.Abilities
+	text := ability
+	switch ability {
+	case "builder":
+		text = fmt.Sprintf("builder %d BP", m.properties.Buildpower)
+		if a.BuildDistance != 0 {
+			text = fmt.Sprintf("%s, %s range", text, formatFloat(a.BuildDistance))
+		}
+	case "cloak":
+		text = fmt.Sprintf("cloak %s/%s E/s", formatFloat(a.CloakCost), formatFloat(a.CloakCostMoving))
+	case "transport":
+		text = fmt.Sprintf("transport %d", a.TransportCapacity)
+	}
+	return badgeStyle.Background(lipgloss.Color(abilityColors[ability])).Render(text)
+}
+
+// rawParams lists the unit and weapon customparams as they are defined in the
+// unit file.
+func (m *Unit) rawParams() []string {
+	lines := make([]string, 0)
+	for _, kv := range m.properties.CustomParams.Params.Flatten() {
+		lines = append(lines, fmt.Sprintf("%s %s", labelStyle.Render(kv[0]), util.MarkTweaked(m.ref, "CustomParams.Params."+kv[0], kv[1])))
+	}
+	weaponNames := make([]string, 0)
+	for name := range m.properties.WeaponDefs {
+		weaponNames = append(weaponNames, name)
+	}
+	sort.Strings(weaponNames)
+	for _, name := range weaponNames {
+		for _, kv := range m.properties.WeaponDefs[name].CustomParams.Flatten() {
+			path := fmt.Sprintf("weapondefs.%s.%s", name, kv[0])
+			lines = append(lines, fmt.Sprintf("%s %s", labelStyle.Render(path), kv[1]))
+		}
+	}
+	if len(lines) == 0 {
+		return lines
+	}
+	return append([]string{labelStyle.Render("Custom params:")}, lines...)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
 func (m *Unit) View() string {
+	return lipgloss.JoinVertical(lipgloss.Left, m.viewport.View(), m.footer())
+}
+
+// renderContent renders every section of the unit and records where the
+// sections and links start.
+func (m *Unit) renderContent() string {
 	var sections []string
+	// marks holds the index in sections every named section starts at
+	type mark struct {
+		name  string
+		index int
+	}
+	marks := make([]mark, 0)
+	markSection := func(name string) {
+		marks = append(marks, mark{name, len(sections)})
+	}
+	linkSections := make(map[int]linkSection)
 
 	var titleRow []string
 	titleRow = append(titleRow, lipgloss.NewStyle().
-		Background(lipgloss.Color(factionColors[m.faction])).
+		Background(lipgloss.Color(util.ColorForFaction(m.faction))).
 		Foreground(lipgloss.Color("230")).
 		Padding(0, 1).
 		Margin(0, 4, 0, 0).
@@ -210,27 +595,35 @@ func (m *Unit) View() string {
 	description := descriptionStyle.Render(m.description)
 	sections = append(sections, description)
 
+	if abilities := m.properties.AbilityNames(); len(abilities) > 0 {
+		badges := make([]string, 0)
+		for _, ability := range abilities {
+			badges = append(badges, m.abilityBadge(ability))
+		}
+		sections = append(sections, padding.Render(util.MarkTweaked(m.ref, "Abilities", lipgloss.JoinHorizontal(lipgloss.Top, badges...))))
+	}
+
 	d := time.Second * time.Duration(m.properties.Buildtime/100)
 	stats := [][]string{
-		{"Metal cost", m.metalCost.ViewAs(m.PercentageWithBase(m.properties.MetalCost, m.baseValues.MetalCost)), strconv.FormatInt(m.properties.MetalCost, 10)},
-		{"Energy cost", m.energyCost.ViewAs(m.PercentageWithBase(m.properties.EnergyCost, m.baseValues.EnergyCost)), strconv.FormatInt(m.properties.EnergyCost, 10)},
-		{"Buildtime", m.buildtime.ViewAs(m.PercentageWithBase(m.properties.Buildtime, m.baseValues.Buildtime)), d.String()},
-		{"Health", m.health.ViewAs(m.PercentageWithBase(m.properties.Health, m.baseValues.Health)), strconv.FormatInt(m.properties.Health, 10)},
-		{"Speed", m.speed.ViewAs(m.PercentageWithBaseF(m.properties.Speed, m.baseValues.Speed)), strconv.FormatFloat(m.properties.Speed, 'f', 1, 64)},
-		{"Sight range", m.sightRange.ViewAs(m.PercentageWithBase(m.properties.SightDistance, m.baseValues.SightDistance)), strconv.FormatInt(m.properties.SightDistance, 10)},
+		{"Metal cost", m.metalCost.ViewAs(m.PercentageWithBase(m.properties.MetalCost, m.baseValues.MetalCost)), util.MarkTweaked(m.ref, "MetalCost", strconv.FormatInt(m.properties.MetalCost, 10))},
+		{"Energy cost", m.energyCost.ViewAs(m.PercentageWithBase(m.properties.EnergyCost, m.baseValues.EnergyCost)), util.MarkTweaked(m.ref, "EnergyCost", strconv.FormatInt(m.properties.EnergyCost, 10))},
+		{"Buildtime", m.buildtime.ViewAs(m.PercentageWithBase(m.properties.Buildtime, m.baseValues.Buildtime)), util.MarkTweaked(m.ref, "Buildtime", d.String())},
+		{"Health", m.health.ViewAs(m.PercentageWithBase(m.properties.Health, m.baseValues.Health)), util.MarkTweaked(m.ref, "Health", strconv.FormatInt(m.properties.Health, 10))},
+		{"Speed", m.speed.ViewAs(m.PercentageWithBaseF(m.properties.Speed, m.baseValues.Speed)), util.MarkTweaked(m.ref, "Speed", strconv.FormatFloat(m.properties.Speed, 'f', 1, 64))},
+		{"Sight range", m.sightRange.ViewAs(m.PercentageWithBase(m.properties.SightDistance, m.baseValues.SightDistance)), util.MarkTweaked(m.ref, "SightDistance", strconv.FormatInt(m.properties.SightDistance, 10))},
 	}
 
 	if m.properties.RadarDistance != 0 {
-		stats = append(stats, []string{"Radar range", m.radarRange.ViewAs(m.PercentageWithBase(m.properties.RadarDistance, m.baseValues.RadarDistance)), strconv.FormatInt(m.properties.RadarDistance, 10)})
+		stats = append(stats, []string{"Radar range", m.radarRange.ViewAs(m.PercentageWithBase(m.properties.RadarDistance, m.baseValues.RadarDistance)), util.MarkTweaked(m.ref, "RadarDistance", strconv.FormatInt(m.properties.RadarDistance, 10))})
 	}
 	if m.properties.JammerDistance != 0 {
-		stats = append(stats, []string{"Jammer range", m.jammerRange.ViewAs(m.PercentageWithBase(m.properties.JammerDistance, m.baseValues.JammerDistance)), strconv.FormatInt(m.properties.JammerDistance, 10)})
+		stats = append(stats, []string{"Jammer range", m.jammerRange.ViewAs(m.PercentageWithBase(m.properties.JammerDistance, m.baseValues.JammerDistance)), util.MarkTweaked(m.ref, "JammerDistance", strconv.FormatInt(m.properties.JammerDistance, 10))})
 	}
 	if m.properties.SonarDistance != 0 {
-		stats = append(stats, []string{"Sonar range", m.sonarRange.ViewAs(m.PercentageWithBase(m.properties.SonarDistance, m.baseValues.SonarDistance)), strconv.FormatInt(m.properties.SonarDistance, 10)})
+		stats = append(stats, []string{"Sonar range", m.sonarRange.ViewAs(m.PercentageWithBase(m.properties.SonarDistance, m.baseValues.SonarDistance)), util.MarkTweaked(m.ref, "SonarDistance", strconv.FormatInt(m.properties.SonarDistance, 10))})
 	}
 	if m.properties.Buildpower != 0 {
-		stats = append(stats, []string{"Buildpower", m.buildpower.ViewAs(m.PercentageWithBase(m.properties.Buildpower, m.baseValues.Buildpower)), strconv.FormatInt(m.properties.Buildpower, 10)})
+		stats = append(stats, []string{"Buildpower", m.buildpower.ViewAs(m.PercentageWithBase(m.properties.Buildpower, m.baseValues.Buildpower)), util.MarkTweaked(m.ref, "Buildpower", strconv.FormatInt(m.properties.Buildpower, 10))})
 	}
 
 	ws := weaponStyle
@@ -239,8 +632,8 @@ func (m *Unit) View() string {
 	}
 	weaponStats := [][]string{
 		{"Weapons", ws.Render(m.properties.SummarizeWeaponTypes()), ""},
-		{"DPS", m.weaponDps.ViewAs(m.PercentageWithBase(int64(math.Round(m.properties.DPS())), m.baseValues.DPS)), strconv.Itoa(int(math.Round(m.properties.DPS())))},
-		{"Weapon range", m.weaponRange.ViewAs(m.PercentageWithBase(int64(m.properties.MaxWeaponRange()), m.baseValues.WeaponRange)), strconv.Itoa(int(m.properties.MaxWeaponRange()))},
+		{"DPS", m.weaponDps.ViewAs(m.PercentageWithBase(int64(math.Round(m.properties.DPS())), m.baseValues.DPS)), util.MarkTweaked(m.ref, "WeaponDefs", strconv.Itoa(int(math.Round(m.properties.DPS()))))},
+		{"Weapon range", m.weaponRange.ViewAs(m.PercentageWithBase(int64(m.properties.MaxWeaponRange()), m.baseValues.WeaponRange)), util.MarkTweaked(m.ref, "WeaponDefs", strconv.Itoa(int(m.properties.MaxWeaponRange())))},
 	}
 	if m.properties.MPS() != 0.0 {
 		weaponStats = append(weaponStats, []string{"Metal/s", m.weaponMps.ViewAs(m.PercentageWithBase(int64(math.Round(m.properties.MPS())), m.baseValues.MPS)), strconv.Itoa(int(math.Round(m.properties.MPS())))})
@@ -252,7 +645,46 @@ func (m *Unit) View() string {
 		weaponStats = append(weaponStats, []string{"Paralyze time", m.weaponParalyzeTime.ViewAs(m.PercentageWithBase(int64(m.properties.ParalyzeTime()), m.baseValues.ParalyzeTime)), strconv.FormatInt(m.properties.ParalyzeTime(), 10)})
 	}
 
+	mobilityStats := make([][]string, 0)
+	if mv := m.properties.Movement; !m.properties.IsBuilding() || mv.CanFly {
+		domain := m.properties.Domain()
+		if mv.MovementClass != "" {
+			domain = fmt.Sprintf("%s %s", domain, helpStyle.Render("("+mv.MovementClass+")"))
+		}
+		mobilityStats = append(mobilityStats,
+			[]string{"Domain", util.MarkTweaked(m.ref, "Movement.MovementClass", domain), ""},
+			[]string{"Acceleration", util.MarkTweaked(m.ref, "Movement.MaxAcc", formatFloat(mv.MaxAcc)), ""},
+			[]string{"Braking", util.MarkTweaked(m.ref, "Movement.MaxDec", formatFloat(mv.MaxDec)), ""},
+			[]string{"Turn rate", util.MarkTweaked(m.ref, "Movement.TurnRate", formatFloat(mv.TurnRate)), ""},
+		)
+		if mv.CanFly {
+			mobilityStats = append(mobilityStats, []string{"Cruise altitude", util.MarkTweaked(m.ref, "Movement.CruiseAltitude", formatFloat(mv.CruiseAltitude)), ""})
+		} else {
+			mobilityStats = append(mobilityStats, []string{"Max slope", util.MarkTweaked(m.ref, "Movement.MaxSlope", formatFloat(mv.MaxSlope)), ""})
+			mobilityStats = append(mobilityStats, []string{"Water depth", util.MarkTweaked(m.ref, "Movement.MaxWaterDepth", fmt.Sprintf("%s - %s", formatFloat(mv.MinWaterDepth), formatFloat(mv.MaxWaterDepth))), ""})
+		}
+	}
+
+	explosionStats := make([][]string, 0)
+	if wd, ok := util.DeathExplosion(m.properties); ok {
+		explosionStats = append(explosionStats, []string{"Death explosion", util.MarkTweaked(m.ref, "ExplodeAs", util.FormatExplosion(wd)) + " " + helpStyle.Render("("+m.properties.ExplodeAs+")"), ""})
+	}
+	if wd, ok := util.SelfDestructExplosion(m.properties); ok {
+		explosionStats = append(explosionStats, []string{"Self-destruct", util.MarkTweaked(m.ref, "SelfDestructAs", util.FormatExplosion(wd)) + " " + helpStyle.Render("("+m.properties.SelfDestructAs+")"), ""})
+	}
+
+	reclaimStats := make([][]string, 0)
+	if m.properties.Wreck != (types.FeatureDef{}) {
+		reclaimStats = append(reclaimStats, []string{util.WreckLabel(), util.MarkTweaked(m.ref, "Wreck", util.FormatFeatureDef(m.properties.Wreck)), ""})
+	}
+	if m.properties.Heap != (types.FeatureDef{}) {
+		reclaimStats = append(reclaimStats, []string{util.HeapLabel(), util.MarkTweaked(m.ref, "Heap", util.FormatFeatureDef(m.properties.Heap)), ""})
+	}
+
 	allStats := append(stats, weaponStats...)
+	allStats = append(allStats, mobilityStats...)
+	allStats = append(allStats, explosionStats...)
+	allStats = append(allStats, reclaimStats...)
 
 	maxLabelWidth := 0
 	maxValueWidth := 0
@@ -261,6 +693,7 @@ func (m *Unit) View() string {
 		maxValueWidth = max(ansi.StringWidth(stat[2]), maxValueWidth)
 	}
 
+	markSection("Stats")
 	for _, stat := range stats {
 		sections = append(sections, m.RenderBar(maxLabelWidth, stat[0], stat[1], maxValueWidth, stat[2]))
 	}
@@ -270,7 +703,80 @@ func (m *Unit) View() string {
 		weaponSections = append(weaponSections, m.RenderBar(maxLabelWidth, stat[0], stat[1], maxValueWidth, stat[2]))
 	}
 
+	// Unarmed units show the zero DPS but have no section to jump to
+	if len(m.properties.Weapons) > 0 {
+		markSection("Weapons")
+	}
 	sections = append(sections, padding.Render(lipgloss.JoinVertical(lipgloss.Left, weaponSections...)))
 
+	if len(mobilityStats) > 0 {
+		mobilitySections := make([]string, 0)
+		for _, stat := range mobilityStats {
+			mobilitySections = append(mobilitySections, m.RenderBar(maxLabelWidth, stat[0], stat[1], maxValueWidth, stat[2]))
+		}
+		markSection("Mobility")
+		sections = append(sections, padding.Render(lipgloss.JoinVertical(lipgloss.Left, mobilitySections...)))
+	}
+
+	if len(explosionStats) > 0 {
+		explosionSections := make([]string, 0)
+		for _, stat := range explosionStats {
+			explosionSections = append(explosionSections, m.RenderBar(maxLabelWidth, stat[0], stat[1], maxValueWidth, stat[2]))
+		}
+		markSection("Explosions")
+		sections = append(sections, lipgloss.JoinVertical(lipgloss.Left, explosionSections...))
+	}
+
+	if len(reclaimStats) > 0 {
+		reclaimSections := make([]string, 0)
+		for _, stat := range reclaimStats {
+			reclaimSections = append(reclaimSections, m.RenderBar(maxLabelWidth, stat[0], stat[1], maxValueWidth, stat[2]))
+		}
+		markSection("Reclaim")
+		sections = append(sections, padding.Render(lipgloss.JoinVertical(lipgloss.Left, reclaimSections...)))
+	}
+
+	if !m.hideLinks {
+		for _, ls := range m.linkSections() {
+			markSection(ls.name)
+			linkSections[len(sections)] = ls
+			sections = append(sections, ls.view)
+		}
+	}
+
+	if params := m.rawParams(); len(params) > 0 {
+		markSection("Custom params")
+		sections = append(sections, padding.Render(lipgloss.JoinVertical(lipgloss.Left, params...)))
+	}
+
+	if gamedata.IsUsingTweakedUnitProperties() {
+		if diffs := util.TweakDiff(m.ref); len(diffs) > 0 {
+			tweakLines := []string{labelStyle.Render("Tweaked:")}
+			for _, d := range diffs {
+				tweakLines = append(tweakLines, fmt.Sprintf("%s %s → %s", labelStyle.Render(d.Path), d.Old, tweakedStyle.Render(d.New)))
+			}
+			markSection("Tweaked")
+			sections = append(sections, padding.Render(lipgloss.JoinVertical(lipgloss.Left, tweakLines...)))
+		}
+	}
+
+	// Sections are stacked, each starts below the lines of the previous ones
+	starts := make([]int, len(sections))
+	line := 0
+	for i, section := range sections {
+		starts[i] = line
+		line += lipgloss.Height(section)
+	}
+	m.sections = make([]unitSection, 0, len(marks))
+	for _, mk := range marks {
+		m.sections = append(m.sections, unitSection{mk.name, starts[mk.index]})
+	}
+	m.linkLines = make([]int, len(m.links))
+	for index, ls := range linkSections {
+		for i, l := range ls.lines {
+			m.linkLines[i] = starts[index] + l
+		}
+	}
+
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
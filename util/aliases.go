@@ -0,0 +1,162 @@
+package util
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+// AliasesFileEnv overrides the location of the user aliases file.
+const AliasesFileEnv = "BAR_UNIT_INFO_ALIASES"
+
+// bundledAliases maps community nicknames and former unit names to refs.
+//
+//go:embed aliases.json
+var bundledAliases []byte
+
+// AliasRegistry resolves nicknames and former names of units to refs, the
+// aliases are case insensitive.
+type AliasRegistry struct {
+	refs    map[string]types.UnitRef
+	aliases map[types.UnitRef][]string
+}
+
+func NewAliasRegistry() *AliasRegistry {
+	return &AliasRegistry{
+		refs:    make(map[string]types.UnitRef),
+		aliases: make(map[types.UnitRef][]string),
+	}
+}
+
+// Add registers alias for ref, replacing an earlier alias with the same name.
+func (r *AliasRegistry) Add(alias string, ref types.UnitRef) {
+	alias = strings.ToLower(strings.TrimSpace(alias))
+	ref = strings.ToLower(ref)
+	if previous, ok := r.refs[alias]; ok {
+		r.aliases[previous] = removeString(r.aliases[previous], alias)
+	}
+	r.refs[alias] = ref
+	r.aliases[ref] = append(r.aliases[ref], alias)
+	sort.Strings(r.aliases[ref])
+}
+
+// Load adds the aliases of a JSON object mapping aliases to refs.
+func (r *AliasRegistry) Load(data []byte) error {
+	aliases := make(map[string]types.UnitRef)
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return err
+	}
+	for alias, ref := range aliases {
+		r.Add(alias, ref)
+	}
+	return nil
+}
+
+// Resolve returns the ref alias points to.
+func (r *AliasRegistry) Resolve(alias string) (types.UnitRef, bool) {
+	ref, ok := r.refs[strings.ToLower(strings.TrimSpace(alias))]
+	return ref, ok
+}
+
+// ForRef returns the sorted aliases of ref.
+func (r *AliasRegistry) ForRef(ref types.UnitRef) []string {
+	return r.aliases[ref]
+}
+
+func removeString(s []string, v string) []string {
+	result := make([]string, 0, len(s))
+	for _, e := range s {
+		if e != v {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// UserAliasesFile returns the path of the aliases file users can extend the
+// bundled aliases with, AliasesFileEnv takes precedence over the default in
+// the user config directory.
+func UserAliasesFile() string {
+	if f := os.Getenv(AliasesFileEnv); f != "" {
+		return f
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "bar-unit-info", "aliases.json")
+}
+
+var aliasRegistry *AliasRegistry
+
+// LoadAliases loads the bundled aliases and the user aliases file, entries of
+// the user file take precedence. A missing user file isn't an error.
+func LoadAliases() error {
+	r := NewAliasRegistry()
+	if err := r.Load(bundledAliases); err != nil {
+		return fmt.Errorf("bundled aliases: %w", err)
+	}
+	aliasRegistry = r
+
+	file := UserAliasesFile()
+	if file == "" {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := r.Load(data); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return nil
+}
+
+// GetAliasRegistry returns the loaded aliases, only the bundled aliases when
+// LoadAliases wasn't called.
+func GetAliasRegistry() *AliasRegistry {
+	if aliasRegistry == nil {
+		aliasRegistry = NewAliasRegistry()
+		aliasRegistry.Load(bundledAliases)
+	}
+	return aliasRegistry
+}
+
+func AliasesForRef(ref types.UnitRef) []string {
+	return GetAliasRegistry().ForRef(ref)
+}
+
+// ResolveUnit returns the ref of the unit called name, which can be a ref, an
+// alias or a translated name. Names used by several units resolve to the
+// buildable non PvE unit.
+func ResolveUnit(name string) (types.UnitRef, bool) {
+	name = strings.TrimSpace(name)
+	if _, ok := gamedata.GetUnitPropertiesByRef(strings.ToLower(name)); ok {
+		return strings.ToLower(name), true
+	}
+	if ref, ok := GetAliasRegistry().Resolve(name); ok {
+		if _, exists := gamedata.GetUnitPropertiesByRef(ref); exists {
+			return ref, true
+		}
+	}
+	for _, refs := range [][]types.UnitRef{BuildableUnits(false), AllRefs()} {
+		for _, ref := range refs {
+			if strings.EqualFold(NameForRef(ref), name) {
+				return ref, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,85 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+func TestAliasRegistry(t *testing.T) {
+	r := NewAliasRegistry()
+	if err := r.Load([]byte(`{"Flea": "armflea", "pewee": "ARMPW", "peewee": "armpw"}`)); err != nil {
+		t.Fatal(err)
+	}
+	// User aliases replace bundled aliases with the same name
+	r.Add(" flea ", "corak")
+
+	resolveTests := []struct {
+		alias  string
+		want   types.UnitRef
+		wantOk bool
+	}{
+		{"pewee", "armpw", true},
+		{"PeeWee", "armpw", true},
+		{"  peewee ", "armpw", true},
+		{"flea", "corak", true},
+		{"tick", "", false},
+		{"", "", false},
+	}
+	for _, tt := range resolveTests {
+		t.Run(tt.alias, func(t *testing.T) {
+			got, ok := r.Resolve(tt.alias)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("Resolve(%q) = %q, %v, want %q, %v", tt.alias, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+
+	forRefTests := []struct {
+		ref  types.UnitRef
+		want []string
+	}{
+		{"armpw", []string{"peewee", "pewee"}},
+		{"corak", []string{"flea"}},
+		{"armflea", []string{}},
+		{"armmex", nil},
+	}
+	for _, tt := range forRefTests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if got := r.ForRef(tt.ref); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ForRef(%q) = %#v, want %#v", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAliases(t *testing.T) {
+	t.Cleanup(func() { aliasRegistry = nil })
+
+	t.Setenv(AliasesFileEnv, filepath.Join(t.TempDir(), "missing.json"))
+	if err := LoadAliases(); err != nil {
+		t.Fatalf("LoadAliases() with a missing user file = %v", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "aliases.json")
+	t.Setenv(AliasesFileEnv, file)
+	if err := os.WriteFile(file, []byte(`{"mypawn": "armpw"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadAliases(); err != nil {
+		t.Fatal(err)
+	}
+	if ref, ok := GetAliasRegistry().Resolve("mypawn"); !ok || ref != "armpw" {
+		t.Errorf("Resolve(%q) = %q, %v, want %q, true", "mypawn", ref, ok, "armpw")
+	}
+
+	if err := os.WriteFile(file, []byte(`not json`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadAliases(); err == nil {
+		t.Error("LoadAliases() with an invalid user file succeeded")
+	}
+}
@@ -6,14 +6,35 @@ import (
 	"io/fs"
 	"os"
 	"regexp"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/lukegb/dds"
 	"github.com/wezzle/bar-unit-info/gamedata"
 	"github.com/wezzle/bar-unit-info/gamedata/types"
 )
 
+// SetLanguage switches unit names, descriptions, faction names and the search
+// index to lang, it returns false when the data has no translations for lang.
+func SetLanguage(lang string) bool {
+	if !gamedata.SetLanguage(lang) {
+		return false
+	}
+	factionRegistry = nil
+	searchIndex = nil
+	return true
+}
+
 func NameForRef(ref types.UnitRef) string {
-	return gamedata.GetTranslations().Units.Names[ref]
+	name, ok := gamedata.GetTranslations().Units.Names[ref]
+	if !ok && strings.HasSuffix(ref, "_scav") {
+		// Scavenger variants share the translation of the unit they're based on
+		if baseName, ok := gamedata.GetTranslations().Units.Names[strings.TrimSuffix(ref, "_scav")]; ok {
+			return fmt.Sprintf("%s (%s)", baseName, gamedata.GetTranslations().Units.Scavenger)
+		}
+	}
+	return name
 }
 
 func DescriptionForRef(ref types.UnitRef) string {
@@ -21,19 +42,27 @@ func DescriptionForRef(ref types.UnitRef) string {
 }
 
 func FactionForRef(ref types.UnitRef) string {
-	shortcode := ref[0:3]
-	if shortcode == "lee" {
-		shortcode = "leg"
+	if up, ok := gamedata.GetUnitPropertiesByRef(ref); ok && up.IsPvE() {
+		if f, ok := GetFactionRegistry().BySource(up.Source); ok {
+			return f.Name
+		}
 	}
-	return gamedata.GetTranslations().Units.Factions[shortcode]
+	f, _, _ := GetFactionRegistry().ForRef(ref)
+	return f.Name
+}
+
+// Factions returns the faction names in registry order.
+func Factions(includeRandom bool) []string {
+	factions := []string{}
+	for _, f := range GetFactionRegistry().All(includeRandom) {
+		factions = append(factions, f.Name)
+	}
+	return factions
 }
 
 func OtherFactions(faction string, includeRandom bool) []string {
 	factions := []string{}
-	for _, f := range gamedata.GetTranslations().Units.Factions {
-		if f == "Random" && !includeRandom {
-			continue
-		}
+	for _, f := range Factions(includeRandom) {
 		if f != faction {
 			factions = append(factions, f)
 		}
@@ -41,13 +70,93 @@ func OtherFactions(faction string, includeRandom bool) []string {
 	return factions
 }
 
+// PrefixForFaction returns the ref prefix of faction, "random" for factions
+// that aren't registered.
 func PrefixForFaction(faction string) string {
-	for prefix, data := range gamedata.GetTranslations().Units.Factions {
-		if data == faction {
-			return prefix
+	f, ok := GetFactionRegistry().ByName(faction)
+	if !ok {
+		return "random"
+	}
+	return f.Prefix
+}
+
+func ColorForFaction(faction string) string {
+	f, ok := GetFactionRegistry().ByName(faction)
+	if !ok {
+		return defaultFactionColor
+	}
+	return f.Color
+}
+
+// AllRefs returns the sorted refs of every unit in the data.
+func AllRefs() []types.UnitRef {
+	refs := make([]types.UnitRef, 0)
+	for ref := range gamedata.GetUnitProperties() {
+		if ref == "" {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// BuildableUnits returns the sorted refs of every unit that can be built from
+// a lab, directly or through a constructor. PvE units aren't reachable from
+// labs and are appended when includePvE is set.
+func BuildableUnits(includePvE bool) []types.UnitRef {
+	buildableUnits := make([]types.UnitRef, 0)
+	seen := make(map[types.UnitRef]bool)
+
+	// Use labs to find buildable units
+	for ref := range gamedata.GetLabGrid() {
+		up, ok := gamedata.GetUnitPropertiesByRef(ref)
+		if !ok {
+			continue
+		}
+		for _, boRef := range up.BuildOptions {
+			if _, ok := gamedata.GetUnitPropertiesByRef(boRef); !ok || seen[boRef] {
+				continue
+			}
+			buildableUnits = append(buildableUnits, boRef)
+			seen[boRef] = true
+		}
+	}
+
+	// Check all buildable units for buildable units of their own
+	for i := 0; i < len(buildableUnits); i++ {
+		up, _ := gamedata.GetUnitPropertiesByRef(buildableUnits[i])
+		for _, boRef := range up.BuildOptions {
+			if _, ok := gamedata.GetUnitPropertiesByRef(boRef); !ok || seen[boRef] {
+				continue
+			}
+			buildableUnits = append(buildableUnits, boRef)
+			seen[boRef] = true
 		}
 	}
-	return "random"
+
+	if includePvE {
+		for ref, up := range gamedata.GetUnitProperties() {
+			if up.IsPvE() && !seen[ref] {
+				buildableUnits = append(buildableUnits, ref)
+				seen[ref] = true
+			}
+		}
+	}
+
+	sort.Strings(buildableUnits)
+	return buildableUnits
+}
+
+// ModOptionsSummary returns the modoptions the unit data was generated with as
+// a sorted, comma separated list of key=value pairs.
+func ModOptionsSummary() string {
+	pairs := make([]string, 0)
+	for k, v := range gamedata.GetModOptions() {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
 }
 
 func LoadImage(ref types.UnitRef) image.Image {
@@ -82,12 +191,43 @@ func findUnitPropertiesFile(ref types.UnitRef) (string, error) {
 	return file, nil
 }
 
+// constructorSuffixes returns the part after the faction prefix for every
+// constructor in the unit grid, e.g. "ck" for "armck". The result is sorted so
+// lookups are deterministic.
+func constructorSuffixes() []string {
+	suffixes := make([]string, 0)
+	for constructor := range gamedata.GetUnitGrid() {
+		suffixes = append(suffixes, suffixForRef(constructor))
+	}
+	sort.Strings(suffixes)
+	return RemoveDuplicate(suffixes)
+}
+
+// labSuffixes returns the part after the faction prefix for every lab in the
+// lab grid, e.g. "lab" for "armlab" or "avp" for "coravp".
+func labSuffixes() []string {
+	suffixes := make([]string, 0)
+	for lab := range gamedata.GetLabGrid() {
+		suffixes = append(suffixes, suffixForRef(lab))
+	}
+	sort.Strings(suffixes)
+	return RemoveDuplicate(suffixes)
+}
+
+func suffixForRef(ref types.UnitRef) string {
+	_, prefix, ok := GetFactionRegistry().ForRef(ref)
+	if !ok {
+		return ""
+	}
+	return strings.TrimPrefix(ref, prefix)
+}
+
 func CounterpartForBuilding(ref types.UnitRef) []types.UnitRef {
 	refs := make([]types.UnitRef, 0)
 	constructors := gamedata.IsBuiltByUnits(ref)
 	faction := FactionForRef(ref)
 	for _, otherFaction := range OtherFactions(faction, false) {
-		for _, constructorSuffix := range []string{"ca", "aca", "cv", "acv", "ck", "ack", "acsub"} {
+		for _, constructorSuffix := range constructorSuffixes() {
 			constructorRef := fmt.Sprintf("%s%s", PrefixForFaction(faction), constructorSuffix)
 			path, exists := constructors[constructorRef]
 			if !exists {
@@ -100,9 +240,99 @@ func CounterpartForBuilding(ref types.UnitRef) []types.UnitRef {
 				continue
 			}
 
-			refs = append(refs, fConstructorGroup[path[0]][path[1]][path[2]])
+			if path[0] >= len(fConstructorGroup) || path[1] >= len(fConstructorGroup[path[0]]) || path[2] >= len(fConstructorGroup[path[0]][path[1]]) {
+				continue
+			}
+			counterpart := fConstructorGroup[path[0]][path[1]][path[2]]
+			if counterpart == "" {
+				continue
+			}
+			refs = append(refs, counterpart)
 			break
 		}
 	}
 	return refs
 }
+
+// CounterpartForUnit finds equivalent lab-built units for the other factions by
+// matching the slot the unit occupies in its lab with the same slot in the
+// other faction's lab of the same type.
+func CounterpartForUnit(ref types.UnitRef) []types.UnitRef {
+	refs := make([]types.UnitRef, 0)
+	labs := gamedata.IsBuiltByLabs(ref)
+	faction := FactionForRef(ref)
+	for _, otherFaction := range OtherFactions(faction, false) {
+		for _, labSuffix := range labSuffixes() {
+			labRef := fmt.Sprintf("%s%s", PrefixForFaction(faction), labSuffix)
+			path, exists := labs[labRef]
+			if !exists {
+				continue
+			}
+
+			fLabRef := fmt.Sprintf("%s%s", PrefixForFaction(otherFaction), labSuffix)
+			fLabRow, exists := gamedata.GetLabGrid()[fLabRef]
+			if !exists {
+				continue
+			}
+
+			if path[0] >= len(fLabRow) || path[1] >= len(fLabRow[path[0]]) {
+				continue
+			}
+			counterpart := fLabRow[path[0]][path[1]]
+			if counterpart == "" {
+				continue
+			}
+			refs = append(refs, counterpart)
+			break
+		}
+	}
+	return refs
+}
+
+// Counterparts returns the cross-faction equivalents of ref, using the
+// constructor grid for buildings and the lab grid for mobile units.
+func Counterparts(ref types.UnitRef) []types.UnitRef {
+	if up, ok := gamedata.GetUnitPropertiesByRef(ref); ok && up.IsBuilding() {
+		return CounterpartForBuilding(ref)
+	}
+	return CounterpartForUnit(ref)
+}
+
+// BuiltBy returns the sorted refs of the constructors and labs that can build
+// ref, from the grid menus and the build options of every unit.
+func BuiltBy(ref types.UnitRef) []types.UnitRef {
+	builders := make([]types.UnitRef, 0)
+	for constructor := range gamedata.IsBuiltByUnits(ref) {
+		builders = append(builders, constructor)
+	}
+	for lab := range gamedata.IsBuiltByLabs(ref) {
+		builders = append(builders, lab)
+	}
+	for builder, up := range gamedata.GetUnitProperties() {
+		if slices.Contains(up.BuildOptions, ref) {
+			builders = append(builders, builder)
+		}
+	}
+	sort.Strings(builders)
+	builders = RemoveDuplicate(builders)
+	return slices.DeleteFunc(builders, func(builder types.UnitRef) bool {
+		_, ok := gamedata.GetUnitPropertiesByRef(builder)
+		return !ok
+	})
+}
+
+// Builds returns the build options of ref that have a unit definition, in the
+// order they're defined.
+func Builds(ref types.UnitRef) []types.UnitRef {
+	up, ok := gamedata.GetUnitPropertiesByRef(ref)
+	if !ok {
+		return nil
+	}
+	refs := make([]types.UnitRef, 0, len(up.BuildOptions))
+	for _, bo := range up.BuildOptions {
+		if _, ok := gamedata.GetUnitPropertiesByRef(bo); ok && !slices.Contains(refs, bo) {
+			refs = append(refs, bo)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+// DeathExplosion returns the shared weapon definition a unit explodes with
+// when it dies.
+func DeathExplosion(up *types.UnitProperties) (types.WeaponDef, bool) {
+	if up.ExplodeAs == "" {
+		return types.WeaponDef{}, false
+	}
+	return gamedata.GetWeaponDef(up.ExplodeAs)
+}
+
+// SelfDestructExplosion returns the shared weapon definition a unit explodes
+// with when self-destructed.
+func SelfDestructExplosion(up *types.UnitProperties) (types.WeaponDef, bool) {
+	if up.SelfDestructAs == "" {
+		return types.WeaponDef{}, false
+	}
+	return gamedata.GetWeaponDef(up.SelfDestructAs)
+}
+
+// FormatExplosion summarizes the default damage and area of effect of an
+// explosion.
+func FormatExplosion(wd types.WeaponDef) string {
+	return fmt.Sprintf("%d damage, %d AoE", int(math.Round(wd.Damage["default"])), int(math.Round(wd.DamageAreaOfEffect)))
+}
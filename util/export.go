@@ -0,0 +1,58 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+// AbilitySeparator separates the values of the abilities column.
+const AbilitySeparator = ", "
+
+var UnitHeader = []string{
+	"Ref", "Faction", "Name", "Tech level", "Metal cost", "Energy cost",
+	"Buildtime", "Health", "Sight range", "Speed", "Domain", "Abilities",
+	"Wreck metal", "Heap metal", "Source", "Custom params",
+}
+
+// UnitRecord returns the values of the unit table columns for ref, matching
+// UnitHeader. Values changed by tweaks are marked with TweakedMarker.
+func UnitRecord(ref types.UnitRef, up *types.UnitProperties) []string {
+	d := time.Second * time.Duration(up.Buildtime/100)
+	return []string{
+		ref,
+		FactionForRef(ref),
+		NameForRef(ref),
+		MarkTweaked(ref, "CustomParams.TechLevel", fmt.Sprintf("T%d", up.CustomParams.TechLevel)),
+		MarkTweaked(ref, "MetalCost", strconv.FormatInt(up.MetalCost, 10)),
+		MarkTweaked(ref, "EnergyCost", strconv.FormatInt(up.EnergyCost, 10)),
+		MarkTweaked(ref, "Buildtime", d.String()),
+		MarkTweaked(ref, "Health", strconv.FormatInt(up.Health, 10)),
+		MarkTweaked(ref, "SightDistance", strconv.FormatInt(up.SightDistance, 10)),
+		MarkTweaked(ref, "Speed", strconv.FormatFloat(up.Speed, 'f', 1, 64)),
+		MarkTweaked(ref, "Movement", up.Domain()),
+		MarkTweaked(ref, "Abilities", strings.Join(up.AbilityNames(), AbilitySeparator)),
+		MarkTweaked(ref, "Wreck.Metal", FormatMetal(up.Wreck.Metal)),
+		MarkTweaked(ref, "Heap.Metal", FormatMetal(up.Heap.Metal)),
+		up.Source,
+		MarkTweaked(ref, "CustomParams.Params", FormatParams(up.CustomParams.Params)),
+	}
+}
+
+// UnitRecords returns the records of all buildable units for export, without
+// TweakedMarker.
+func UnitRecords(includePvE bool) [][]string {
+	records := make([][]string, 0)
+	for _, ref := range BuildableUnits(includePvE) {
+		up, ok := gamedata.GetUnitPropertiesByRef(ref)
+		if !ok {
+			continue
+		}
+		records = append(records, UnmarkTweaked(UnitRecord(ref, up)))
+	}
+	return records
+}
@@ -0,0 +1,224 @@
+package util
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+const (
+	randomFactionPrefix = "random"
+	defaultFactionColor = "240"
+)
+
+type Faction struct {
+	// Prefix is the ref prefix used by the faction's units, e.g. "arm".
+	Prefix string
+	// Aliases are additional ref prefixes that belong to the faction.
+	Aliases []string
+	// Suffixes are ref suffixes that belong to the faction, they take
+	// precedence over prefixes, e.g. "_scav" for Scavenger variants.
+	Suffixes []string
+	// Source is set for PvE factions and matches the source units are tagged
+	// with by the parser.
+	Source    types.UnitSource
+	Name      string
+	Color     string
+	Commander types.UnitRef
+	// Order is used to sort factions, lower values come first. Factions with the
+	// same order are sorted by name.
+	Order int
+}
+
+func (f Faction) IsRandom() bool {
+	return f.Prefix == randomFactionPrefix
+}
+
+func (f Faction) IsPvE() bool {
+	return f.Source != "" && f.Source != types.SourceStock
+}
+
+// Prefixes returns the prefix and all aliases of the faction.
+func (f Faction) Prefixes() []string {
+	return append([]string{f.Prefix}, f.Aliases...)
+}
+
+type FactionRegistry struct {
+	factions []Faction
+}
+
+// factionOverrides holds presentation details for known factions that can't
+// be derived from the translations. Factions missing here still work, they
+// just use default values. Overrides with a name are registered even when the
+// translations don't list them, which is how the PvE factions are added.
+var factionOverrides = map[string]Faction{
+	"arm":               {Color: "27", Order: 1},
+	"cor":               {Color: "124", Order: 2},
+	"leg":               {Color: "34", Order: 3, Aliases: []string{"lee"}},
+	"scav":              {Name: "Scavengers", Color: "93", Order: 10, Suffixes: []string{"_scav"}, Source: types.SourceScavengers},
+	"raptor":            {Name: "Raptors", Color: "130", Order: 11, Source: types.SourceRaptors},
+	randomFactionPrefix: {Order: 100},
+}
+
+var factionRegistry *FactionRegistry
+
+// GetFactionRegistry returns the registry of factions built from the
+// translations and factionOverrides.
+func GetFactionRegistry() *FactionRegistry {
+	if factionRegistry == nil {
+		factionRegistry = NewFactionRegistry(gamedata.GetTranslations().Units.Factions, factionOverrides)
+	}
+	return factionRegistry
+}
+
+func NewFactionRegistry(names map[string]string, overrides map[string]Faction) *FactionRegistry {
+	r := &FactionRegistry{}
+	for prefix, name := range names {
+		f := Faction{
+			Prefix: prefix,
+			Name:   name,
+			Color:  defaultFactionColor,
+			Order:  50,
+		}
+		if o, ok := overrides[prefix]; ok {
+			f.Aliases = o.Aliases
+			f.Suffixes = o.Suffixes
+			f.Source = o.Source
+			f.Order = o.Order
+			if o.Color != "" {
+				f.Color = o.Color
+			}
+			if o.Name != "" {
+				f.Name = o.Name
+			}
+			f.Commander = o.Commander
+		}
+		if f.Commander == "" && !f.IsRandom() && !f.IsPvE() {
+			f.Commander = prefix + "com"
+		}
+		r.Register(f)
+	}
+	for prefix, o := range overrides {
+		if _, exists := names[prefix]; exists || o.Name == "" {
+			continue
+		}
+		f := o
+		f.Prefix = prefix
+		if f.Color == "" {
+			f.Color = defaultFactionColor
+		}
+		r.Register(f)
+	}
+	return r
+}
+
+// Register adds or replaces a faction by prefix.
+func (r *FactionRegistry) Register(f Faction) {
+	for i, existing := range r.factions {
+		if existing.Prefix == f.Prefix {
+			r.factions[i] = f
+			r.sort()
+			return
+		}
+	}
+	r.factions = append(r.factions, f)
+	r.sort()
+}
+
+func (r *FactionRegistry) sort() {
+	sort.SliceStable(r.factions, func(i, j int) bool {
+		if r.factions[i].Order != r.factions[j].Order {
+			return r.factions[i].Order < r.factions[j].Order
+		}
+		return r.factions[i].Name < r.factions[j].Name
+	})
+}
+
+// All returns the playable factions in a deterministic order.
+func (r *FactionRegistry) All(includeRandom bool) []Faction {
+	factions := make([]Faction, 0)
+	for _, f := range r.factions {
+		if f.IsRandom() && !includeRandom {
+			continue
+		}
+		if f.IsPvE() {
+			continue
+		}
+		factions = append(factions, f)
+	}
+	return factions
+}
+
+// PvE returns the PvE factions in a deterministic order.
+func (r *FactionRegistry) PvE() []Faction {
+	factions := make([]Faction, 0)
+	for _, f := range r.factions {
+		if f.IsPvE() {
+			factions = append(factions, f)
+		}
+	}
+	return factions
+}
+
+func (r *FactionRegistry) BySource(source types.UnitSource) (Faction, bool) {
+	for _, f := range r.factions {
+		if f.Source == source {
+			return f, true
+		}
+	}
+	return Faction{}, false
+}
+
+func (r *FactionRegistry) ByName(name string) (Faction, bool) {
+	for _, f := range r.factions {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Faction{}, false
+}
+
+func (r *FactionRegistry) ByPrefix(prefix string) (Faction, bool) {
+	for _, f := range r.factions {
+		for _, p := range f.Prefixes() {
+			if p == prefix {
+				return f, true
+			}
+		}
+	}
+	return Faction{}, false
+}
+
+// ForRef returns the faction whose prefix or alias is the longest match for
+// the start of ref, together with the matched prefix. Faction suffixes are
+// checked first, a suffix match returns an empty prefix.
+func (r *FactionRegistry) ForRef(ref types.UnitRef) (Faction, string, bool) {
+	var (
+		match       Faction
+		matchPrefix string
+	)
+	for _, f := range r.factions {
+		if f.IsRandom() {
+			continue
+		}
+		for _, s := range f.Suffixes {
+			if strings.HasSuffix(ref, s) {
+				return f, "", true
+			}
+		}
+	}
+	for _, f := range r.factions {
+		if f.IsRandom() {
+			continue
+		}
+		for _, p := range f.Prefixes() {
+			if strings.HasPrefix(ref, p) && len(p) > len(matchPrefix) {
+				match = f
+				matchPrefix = p
+			}
+		}
+	}
+	return match, matchPrefix, matchPrefix != ""
+}
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+func testFactionRegistry() *FactionRegistry {
+	return NewFactionRegistry(map[string]string{
+		"arm":    "Armada",
+		"cor":    "Cortex",
+		"leg":    "Legion",
+		"random": "Random",
+	}, factionOverrides)
+}
+
+func TestFactionRegistryForRef(t *testing.T) {
+	tests := []struct {
+		ref        types.UnitRef
+		wantName   string
+		wantPrefix string
+		wantOk     bool
+	}{
+		{"armpw", "Armada", "arm", true},
+		{"corak", "Cortex", "cor", true},
+		{"legcom", "Legion", "leg", true},
+		{"leegmech", "Legion", "lee", true},
+		{"armpw_scav", "Scavengers", "", true},
+		{"legcom_scav", "Scavengers", "", true},
+		{"scavengerbossv4", "Scavengers", "scav", true},
+		{"raptor_land_swarmer_basic_t1_v1", "Raptors", "raptor", true},
+		{"randomunit", "", "", false},
+		{"chicken", "", "", false},
+		{"", "", "", false},
+	}
+	r := testFactionRegistry()
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			f, prefix, ok := r.ForRef(tt.ref)
+			if f.Name != tt.wantName || prefix != tt.wantPrefix || ok != tt.wantOk {
+				t.Errorf("ForRef(%q) = %q, %q, %v, want %q, %q, %v", tt.ref, f.Name, prefix, ok, tt.wantName, tt.wantPrefix, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestNewFactionRegistry(t *testing.T) {
+	tests := []struct {
+		prefix        string
+		wantName      string
+		wantColor     string
+		wantCommander types.UnitRef
+		wantPvE       bool
+	}{
+		{"arm", "Armada", "27", "armcom", false},
+		{"leg", "Legion", "34", "legcom", false},
+		{"scav", "Scavengers", "93", "", true},
+		{"raptor", "Raptors", "130", "", true},
+		{"random", "Random", defaultFactionColor, "", false},
+	}
+	r := testFactionRegistry()
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			f, ok := r.ByPrefix(tt.prefix)
+			if !ok {
+				t.Fatalf("ByPrefix(%q) not found", tt.prefix)
+			}
+			if f.Name != tt.wantName || f.Color != tt.wantColor || f.Commander != tt.wantCommander || f.IsPvE() != tt.wantPvE {
+				t.Errorf("ByPrefix(%q) = %+v", tt.prefix, f)
+			}
+		})
+	}
+}
+
+func TestFactionRegistryOrder(t *testing.T) {
+	r := testFactionRegistry()
+	r.Register(Faction{Prefix: "new", Name: "Newcomers", Order: 50})
+
+	want := []string{"Armada", "Cortex", "Legion", "Newcomers", "Random"}
+	got := make([]string, 0)
+	for _, f := range r.All(true) {
+		got = append(got, f.Name)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("All(true) = %v, want %v", got, want)
+	}
+
+	pve := make([]string, 0)
+	for _, f := range r.PvE() {
+		pve = append(pve, f.Name)
+	}
+	if !slices.Equal(pve, []string{"Scavengers", "Raptors"}) {
+		t.Errorf("PvE() = %v, want [Scavengers Raptors]", pve)
+	}
+}
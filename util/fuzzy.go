@@ -0,0 +1,42 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuzzyMatch reports whether the runes of pattern appear in text in order,
+// ignoring case. The score favours matches at the start of text and of
+// words, consecutive runes and short texts.
+func FuzzyMatch(pattern string, text string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+	if len(p) == 0 {
+		return 0, true
+	}
+
+	score := 0
+	pi := 0
+	previous := -2
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if t[ti] != p[pi] {
+			continue
+		}
+		switch {
+		case ti == 0:
+			score += 10
+		case !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]):
+			score += 8
+		}
+		if ti == previous+1 {
+			score += 5
+		}
+		score++
+		previous = ti
+		pi++
+	}
+	if pi < len(p) {
+		return 0, false
+	}
+	return score*4 - len(t), true
+}
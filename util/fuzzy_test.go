@@ -0,0 +1,78 @@
+package util
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		want    bool
+	}{
+		{"", "Pawn", true},
+		{"", "", true},
+		{"pawn", "Pawn", true},
+		{"PWN", "pawn", true},
+		{"apw", "armpw", true},
+		{"pwa", "armpw", false},
+		{"pawn", "paw", false},
+		{"x", "", false},
+	}
+	for _, tt := range tests {
+		if _, got := FuzzyMatch(tt.pattern, tt.text); got != tt.want {
+			t.Errorf("FuzzyMatch(%q, %q) = %v, want %v", tt.pattern, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyMatchRanking(t *testing.T) {
+	tests := []struct {
+		pattern string
+		texts   []string
+		want    []string
+	}{
+		{
+			// Matches at the start of words rank above matches inside words
+			pattern: "pw",
+			texts:   []string{"armpw", "Pawn", "Plasma Weapon"},
+			want:    []string{"Plasma Weapon", "Pawn", "armpw"},
+		},
+		{
+			// Shorter texts rank first on equal matches
+			pattern: "pawn",
+			texts:   []string{"Pawnshop Laser", "Pawn", "Pawn Launcher"},
+			want:    []string{"Pawn", "Pawn Launcher", "Pawnshop Laser"},
+		},
+		{
+			// Consecutive runes rank above scattered ones
+			pattern: "tick",
+			texts:   []string{"The Incredible Clock Keeper", "Tick"},
+			want:    []string{"Tick", "The Incredible Clock Keeper"},
+		},
+		{
+			pattern: "com",
+			texts:   []string{"corcom", "Armada Commander"},
+			want:    []string{"Armada Commander", "corcom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			scores := make(map[string]int)
+			for _, text := range tt.texts {
+				score, ok := FuzzyMatch(tt.pattern, text)
+				if !ok {
+					t.Fatalf("FuzzyMatch(%q, %q) didn't match", tt.pattern, text)
+				}
+				scores[text] = score
+			}
+			got := append([]string(nil), tt.texts...)
+			sort.SliceStable(got, func(i, j int) bool { return scores[got[i]] > scores[got[j]] })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ranking of %q = %v, want %v (scores %v)", tt.pattern, got, tt.want, scores)
+			}
+		})
+	}
+}
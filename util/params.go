@@ -0,0 +1,97 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+// paramOperators are checked in order so at the same position two character
+// operators win over their one character prefix.
+var paramOperators = []string{"!=", ">=", "<=", "=", ">", "<", "~"}
+
+// MatchParams reports whether params match every space separated term of a
+// query. Terms are a path to check for presence (or absence with a leading
+// "!"), or a path, an operator and a value:
+//
+//	iscommander=true paralyzemultiplier<1 model_author~(?i)^fl decoration.size>=2
+//
+// Values are compared as numbers when both sides are numeric and as case
+// insensitive strings otherwise, "~" matches a regular expression.
+func MatchParams(params types.Params, query string) bool {
+	for _, term := range strings.Fields(query) {
+		if !matchParamTerm(params, term) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchParamTerm(params types.Params, term string) bool {
+	// The first operator splits the term, values may contain operator
+	// characters, e.g. in regular expressions
+	path, op, want := "", "", ""
+	for i := 1; i < len(term) && op == ""; i++ {
+		for _, o := range paramOperators {
+			if strings.HasPrefix(term[i:], o) {
+				path, op, want = term[:i], o, term[i+len(o):]
+				break
+			}
+		}
+	}
+	if op == "" {
+		if strings.HasPrefix(term, "!") {
+			_, ok := params.Value(term[1:])
+			return !ok
+		}
+		_, ok := params.Value(term)
+		return ok
+	}
+
+	v, ok := params.Value(path)
+	if !ok {
+		return op == "!="
+	}
+	if op == "~" {
+		re, err := regexp.Compile(want)
+		return err == nil && re.MatchString(strings.Trim(types.FormatParam(v), `"`))
+	}
+
+	got, gotIsNumber := types.ParamFloat(v)
+	wantNumber, wantIsNumber := types.ParamFloat(want)
+	if gotIsNumber && wantIsNumber {
+		switch op {
+		case "=":
+			return got == wantNumber
+		case "!=":
+			return got != wantNumber
+		case ">":
+			return got > wantNumber
+		case "<":
+			return got < wantNumber
+		case ">=":
+			return got >= wantNumber
+		case "<=":
+			return got <= wantNumber
+		}
+	}
+
+	equal := strings.EqualFold(strings.Trim(types.FormatParam(v), `"`), want)
+	switch op {
+	case "=":
+		return equal
+	case "!=":
+		return !equal
+	}
+	return false
+}
+
+// FormatParams summarizes params as comma separated path=value pairs.
+func FormatParams(params types.Params) string {
+	pairs := make([]string, 0)
+	for _, kv := range params.Flatten() {
+		pairs = append(pairs, kv[0]+"="+kv[1])
+	}
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,60 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+func TestMatchParams(t *testing.T) {
+	params := types.Params{
+		"iscommander":        true,
+		"paralyzemultiplier": 0.5,
+		"techlevel":          1,
+		"model_author":       "FireStorm",
+		"unitgroup":          "weapon",
+		"decoration":         types.Params{"size": 2, "model": "pawn"},
+	}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"   ", true},
+		{"iscommander", true},
+		{"!iscommander", false},
+		{"!canfly", true},
+		{"iscommander=true", true},
+		{"iscommander=false", false},
+		{"paralyzemultiplier<1", true},
+		{"paralyzemultiplier>=0.5", true},
+		{"paralyzemultiplier>0.5", false},
+		{"techlevel=1 unitgroup=WEAPON", true},
+		{"techlevel=1 unitgroup=eco", false},
+		{"techlevel!=2", true},
+		{"canfly!=true", true},
+		{"canfly=true", false},
+		{"model_author~(?i)^fire", true},
+		{"model_author~^fire", false},
+		{"model_author~[", false},
+		{"decoration.size>=2", true},
+		{"decoration.model=pawn", true},
+		{"decoration.missing", false},
+		{"unitgroup~^(weapon|eco)$", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := MatchParams(params, tt.query); got != tt.want {
+				t.Errorf("MatchParams(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatParams(t *testing.T) {
+	params := types.Params{"techlevel": 1, "decoration": types.Params{"size": 2}, "unitgroup": "weapon"}
+	want := `decoration.size=2, techlevel=1, unitgroup="weapon"`
+	if got := FormatParams(params); got != want {
+		t.Errorf("FormatParams() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,272 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+type ParityEntry struct {
+	Faction string
+	Ref     types.UnitRef
+	// Missing is set when the faction has no constructor or lab of the slot's
+	// type at all, as opposed to having one with an empty slot.
+	Missing bool
+
+	MetalCost  int64
+	EnergyCost int64
+	Health     int64
+	DPS        float64
+	Range      float64
+	Speed      float64
+}
+
+type ParitySlot struct {
+	// Source is the constructor or lab suffix shared by all factions, e.g. "ck"
+	// or "lab".
+	Source  string
+	Path    []int
+	Entries []ParityEntry
+}
+
+type ParityReport []ParitySlot
+
+// Name returns a human readable slot position using 1-based indices.
+func (s ParitySlot) Name() string {
+	indices := make([]string, 0)
+	for _, i := range s.Path {
+		indices = append(indices, strconv.Itoa(i+1))
+	}
+	return fmt.Sprintf("%s[%s]", s.Source, strings.Join(indices, ","))
+}
+
+// Base returns the entry other entries are compared to, which is the first
+// faction that has a unit in this slot.
+func (s ParitySlot) Base() (ParityEntry, bool) {
+	for _, e := range s.Entries {
+		if e.Ref != "" {
+			return e, true
+		}
+	}
+	return ParityEntry{}, false
+}
+
+// HasCounterparts reports whether more than one faction has a unit in this slot.
+func (s ParitySlot) HasCounterparts() bool {
+	count := 0
+	for _, e := range s.Entries {
+		if e.Ref != "" {
+			count = count + 1
+		}
+	}
+	return count > 1
+}
+
+func (e ParityEntry) Flag() string {
+	switch {
+	case e.Missing:
+		return "no builder"
+	case e.Ref == "":
+		return "empty slot"
+	}
+	return ""
+}
+
+// PercentageDelta returns the difference between value and base as a
+// percentage of base.
+func PercentageDelta(value float64, base float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return (value - base) / base * 100
+}
+
+func FormatDelta(value float64, base float64) string {
+	if base == 0 && value == 0 {
+		return ""
+	}
+	if base == 0 {
+		return "new"
+	}
+	return fmt.Sprintf("%+d%%", int(math.Round(PercentageDelta(value, base))))
+}
+
+func newParityEntry(faction string, ref types.UnitRef) ParityEntry {
+	e := ParityEntry{Faction: faction, Ref: ref}
+	up, ok := gamedata.GetUnitPropertiesByRef(ref)
+	if !ok {
+		return e
+	}
+	e.MetalCost = up.MetalCost
+	e.EnergyCost = up.EnergyCost
+	e.Health = up.Health
+	e.DPS = up.DPS()
+	e.Range = up.MaxWeaponRange()
+	e.Speed = up.Speed
+	return e
+}
+
+// maxLen returns the largest length of the grids per faction.
+func maxLen[T any](grids map[string]T, length func(T) int) int {
+	n := 0
+	for _, grid := range grids {
+		n = max(n, length(grid))
+	}
+	return n
+}
+
+// lenAt returns the length of s[i], 0 when i is out of range.
+func lenAt[S ~[]E, E ~[]T, T any](s S, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	return len(s[i])
+}
+
+// Parity walks every constructor grid and lab grid slot and lines up the unit
+// each faction has in that slot.
+func Parity() ParityReport {
+	factions := Factions(false)
+	report := make(ParityReport, 0)
+
+	for _, suffix := range constructorSuffixes() {
+		groups := make(map[string]types.Group)
+		for _, faction := range factions {
+			if group, exists := gamedata.GetUnitGrid()[PrefixForFaction(faction)+suffix]; exists {
+				groups[faction] = group
+			}
+		}
+		report = append(report, groupSlots(suffix, factions, groups, newParityEntry)...)
+	}
+
+	for _, suffix := range labSuffixes() {
+		labs := make(map[string]types.GridRow)
+		for _, faction := range factions {
+			if row, exists := gamedata.GetLabGrid()[PrefixForFaction(faction)+suffix]; exists {
+				labs[faction] = row
+			}
+		}
+		report = append(report, labSlots(suffix, factions, labs, newParityEntry)...)
+	}
+
+	return report
+}
+
+// groupSlots lines up the constructor grid slots of factions. Factions missing
+// from groups get a Missing entry and slots without any unit are left out.
+func groupSlots(suffix string, factions []string, groups map[string]types.Group, newEntry func(string, types.UnitRef) ParityEntry) []ParitySlot {
+	slots := make([]ParitySlot, 0)
+	// Walk the largest grid of the factions so no slot is left out
+	for groupIndex := range maxLen(groups, func(g types.Group) int { return len(g) }) {
+		rows := maxLen(groups, func(g types.Group) int { return lenAt(g, groupIndex) })
+		for rowIndex := range rows {
+			cols := maxLen(groups, func(g types.Group) int {
+				if groupIndex >= len(g) {
+					return 0
+				}
+				return lenAt(g[groupIndex], rowIndex)
+			})
+			for colIndex := range cols {
+				slot := ParitySlot{Source: suffix, Path: []int{groupIndex, rowIndex, colIndex}}
+				for _, faction := range factions {
+					group, exists := groups[faction]
+					if !exists {
+						slot.Entries = append(slot.Entries, ParityEntry{Faction: faction, Missing: true})
+						continue
+					}
+					ref := ""
+					if groupIndex < len(group) && rowIndex < len(group[groupIndex]) && colIndex < len(group[groupIndex][rowIndex]) {
+						ref = group[groupIndex][rowIndex][colIndex]
+					}
+					slot.Entries = append(slot.Entries, newEntry(faction, ref))
+				}
+				if _, ok := slot.Base(); ok {
+					slots = append(slots, slot)
+				}
+			}
+		}
+	}
+	return slots
+}
+
+// labSlots lines up the lab grid slots of factions, like groupSlots.
+func labSlots(suffix string, factions []string, labs map[string]types.GridRow, newEntry func(string, types.UnitRef) ParityEntry) []ParitySlot {
+	slots := make([]ParitySlot, 0)
+	for rowIndex := range maxLen(labs, func(r types.GridRow) int { return len(r) }) {
+		cols := maxLen(labs, func(r types.GridRow) int { return lenAt(r, rowIndex) })
+		for colIndex := range cols {
+			slot := ParitySlot{Source: suffix, Path: []int{rowIndex, colIndex}}
+			for _, faction := range factions {
+				row, exists := labs[faction]
+				if !exists {
+					slot.Entries = append(slot.Entries, ParityEntry{Faction: faction, Missing: true})
+					continue
+				}
+				ref := ""
+				if rowIndex < len(row) && colIndex < len(row[rowIndex]) {
+					ref = row[rowIndex][colIndex]
+				}
+				slot.Entries = append(slot.Entries, newEntry(faction, ref))
+			}
+			if _, ok := slot.Base(); ok {
+				slots = append(slots, slot)
+			}
+		}
+	}
+	return slots
+}
+
+var ParityHeader = []string{
+	"Slot", "Faction", "Ref", "Name",
+	"Metal cost", "Metal Δ", "Energy cost", "Energy Δ", "Health", "Health Δ",
+	"DPS", "DPS Δ", "Range", "Range Δ", "Speed", "Speed Δ", "Flags",
+}
+
+// Records flattens the report into one row per faction per slot, matching
+// ParityHeader. Deltas are relative to the slot's base entry.
+func (r ParityReport) Records() [][]string {
+	records := make([][]string, 0)
+	for _, slot := range r {
+		base, _ := slot.Base()
+		for _, e := range slot.Entries {
+			flag := e.Flag()
+			if e.Ref != "" && !slot.HasCounterparts() {
+				flag = "no counterpart"
+			}
+			if e.Ref == "" {
+				records = append(records, []string{slot.Name(), e.Faction, "", "", "", "", "", "", "", "", "", "", "", "", "", "", flag})
+				continue
+			}
+			delta := func(value float64, baseValue float64) string {
+				if e.Faction == base.Faction {
+					return ""
+				}
+				return FormatDelta(value, baseValue)
+			}
+			records = append(records, []string{
+				slot.Name(),
+				e.Faction,
+				e.Ref,
+				NameForRef(e.Ref),
+				strconv.FormatInt(e.MetalCost, 10),
+				delta(float64(e.MetalCost), float64(base.MetalCost)),
+				strconv.FormatInt(e.EnergyCost, 10),
+				delta(float64(e.EnergyCost), float64(base.EnergyCost)),
+				strconv.FormatInt(e.Health, 10),
+				delta(float64(e.Health), float64(base.Health)),
+				strconv.Itoa(int(math.Round(e.DPS))),
+				delta(e.DPS, base.DPS),
+				strconv.Itoa(int(e.Range)),
+				delta(e.Range, base.Range),
+				strconv.FormatFloat(e.Speed, 'f', 1, 64),
+				delta(e.Speed, base.Speed),
+				flag,
+			})
+		}
+	}
+	return records
+}
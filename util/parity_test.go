@@ -0,0 +1,145 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+func refEntry(faction string, ref types.UnitRef) ParityEntry {
+	return ParityEntry{Faction: faction, Ref: ref}
+}
+
+// slotRefs returns the slot names with the ref or flag of every entry.
+func slotRefs(slots []ParitySlot) map[string][]string {
+	refs := make(map[string][]string)
+	for _, slot := range slots {
+		for _, e := range slot.Entries {
+			value := e.Ref
+			if value == "" {
+				value = e.Flag()
+			}
+			refs[slot.Name()] = append(refs[slot.Name()], value)
+		}
+	}
+	return refs
+}
+
+func TestGroupSlots(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups map[string]types.Group
+		want   map[string][]string
+	}{
+		{
+			name: "same size",
+			groups: map[string]types.Group{
+				"Armada": {{{"armmex", "armsolar"}}},
+				"Cortex": {{{"cormex", "corsolar"}}},
+				"Legion": {{{"legmex", "legsolar"}}},
+			},
+			want: map[string][]string{
+				"ck[1,1,1]": {"armmex", "cormex", "legmex"},
+				"ck[1,1,2]": {"armsolar", "corsolar", "legsolar"},
+			},
+		},
+		{
+			name: "different sizes",
+			groups: map[string]types.Group{
+				"Armada": {{{"armmex"}}},
+				"Cortex": {{{"cormex", "corsolar"}, {"corllt"}}, {{"corrad"}}},
+				"Legion": {{{"", "legsolar"}}},
+			},
+			want: map[string][]string{
+				"ck[1,1,1]": {"armmex", "cormex", "empty slot"},
+				"ck[1,1,2]": {"empty slot", "corsolar", "legsolar"},
+				"ck[1,2,1]": {"empty slot", "corllt", "empty slot"},
+				"ck[2,1,1]": {"empty slot", "corrad", "empty slot"},
+			},
+		},
+		{
+			name: "missing builder",
+			groups: map[string]types.Group{
+				"Armada": {{{"armmex"}}},
+				"Cortex": {{{"cormex"}}},
+			},
+			want: map[string][]string{
+				"ck[1,1,1]": {"armmex", "cormex", "no builder"},
+			},
+		},
+		{
+			name: "empty slots are left out",
+			groups: map[string]types.Group{
+				"Armada": {{{"", "armsolar"}}},
+				"Cortex": {{{""}}},
+				"Legion": {{{"", ""}}},
+			},
+			want: map[string][]string{
+				"ck[1,1,2]": {"armsolar", "empty slot", "empty slot"},
+			},
+		},
+	}
+	factions := []string{"Armada", "Cortex", "Legion"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slotRefs(groupSlots("ck", factions, tt.groups, refEntry))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupSlots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabSlots(t *testing.T) {
+	tests := []struct {
+		name string
+		labs map[string]types.GridRow
+		want map[string][]string
+	}{
+		{
+			name: "different sizes",
+			labs: map[string]types.GridRow{
+				"Armada": {{"armpw", "armrock"}},
+				"Cortex": {{"corak"}, {"corstorm"}},
+			},
+			want: map[string][]string{
+				"lab[1,1]": {"armpw", "corak", "no builder"},
+				"lab[1,2]": {"armrock", "empty slot", "no builder"},
+				"lab[2,1]": {"empty slot", "corstorm", "no builder"},
+			},
+		},
+		{
+			name: "no labs",
+			labs: map[string]types.GridRow{},
+			want: map[string][]string{},
+		},
+	}
+	factions := []string{"Armada", "Cortex", "Legion"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slotRefs(labSlots("lab", factions, tt.labs, refEntry))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("labSlots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDelta(t *testing.T) {
+	tests := []struct {
+		value, base float64
+		want        string
+	}{
+		{0, 0, ""},
+		{10, 0, "new"},
+		{110, 100, "+10%"},
+		{50, 100, "-50%"},
+		{100, 100, "+0%"},
+	}
+	for _, tt := range tests {
+		if got := FormatDelta(tt.value, tt.base); got != tt.want {
+			t.Errorf("FormatDelta(%v, %v) = %q, want %q", tt.value, tt.base, got, tt.want)
+		}
+	}
+}
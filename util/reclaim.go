@@ -0,0 +1,46 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+// WreckLabel returns the translated name of a unit's dead wreck.
+func WreckLabel() string {
+	if dead := gamedata.GetTranslations().Units.Dead; dead != "" {
+		return dead
+	}
+	return "Wreck"
+}
+
+// HeapLabel returns the translated name of the heap left by a destroyed wreck.
+func HeapLabel() string {
+	if heap := gamedata.GetTranslations().Units.Heap; heap != "" {
+		return heap
+	}
+	return "Heap"
+}
+
+// FormatMetal formats a reclaim value, fractions are dropped like in game.
+func FormatMetal(metal float64) string {
+	return strconv.FormatFloat(math.Floor(metal), 'f', 0, 64)
+}
+
+// FormatFeatureDef summarizes what reclaiming a corpse yields and how much
+// damage it takes to destroy.
+func FormatFeatureDef(fd types.FeatureDef) string {
+	parts := []string{fmt.Sprintf("%s metal", FormatMetal(fd.Metal))}
+	if fd.Energy != 0 {
+		parts = append(parts, fmt.Sprintf("%s energy", FormatMetal(fd.Energy)))
+	}
+	parts = append(parts, fmt.Sprintf("%s health", FormatMetal(fd.Damage)))
+	if fd.Resurrectable {
+		parts = append(parts, "resurrectable")
+	}
+	return strings.Join(parts, ", ")
+}
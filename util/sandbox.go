@@ -0,0 +1,101 @@
+package util
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+// TweakValue is a single changed unit definition value. Path holds the Lua
+// keys leading to the value, e.g. ["weapondefs", "gun", "reloadtime"].
+type TweakValue struct {
+	Path  []string
+	Value float64
+}
+
+// TweakUnitsLua returns the Lua table for the tweakunits modoption.
+func TweakUnitsLua(tweaks map[types.UnitRef][]TweakValue) string {
+	root := make(map[string]interface{})
+	for ref, values := range tweaks {
+		if len(values) == 0 {
+			continue
+		}
+		unit := make(map[string]interface{})
+		for _, v := range values {
+			current := unit
+			for _, key := range v.Path[:len(v.Path)-1] {
+				next, ok := current[key].(map[string]interface{})
+				if !ok {
+					next = make(map[string]interface{})
+					current[key] = next
+				}
+				current = next
+			}
+			current[v.Path[len(v.Path)-1]] = v.Value
+		}
+		root[ref] = unit
+	}
+	return luaTable(root)
+}
+
+// EncodeTweakUnits returns the base64 encoded tweakunits modoption as it is
+// pasted in a lobby.
+func EncodeTweakUnits(tweaks map[types.UnitRef][]TweakValue) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(TweakUnitsLua(tweaks)))
+}
+
+func luaTable(t map[string]interface{}) string {
+	keys := make([]string, 0)
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	entries := make([]string, 0)
+	for _, k := range keys {
+		var value string
+		switch v := t[k].(type) {
+		case map[string]interface{}:
+			value = luaTable(v)
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			value = fmt.Sprintf("%q", fmt.Sprint(v))
+		}
+		entries = append(entries, fmt.Sprintf("%s=%s", luaKey(k), value))
+	}
+	return fmt.Sprintf("{%s}", strings.Join(entries, ","))
+}
+
+func luaKey(k string) string {
+	for i, r := range k {
+		isLetter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		isDigit := r >= '0' && r <= '9'
+		if !isLetter && (i == 0 || !isDigit) {
+			return fmt.Sprintf("[%q]", k)
+		}
+	}
+	return k
+}
+
+// CostEfficiency returns the DPS and health per 100 metal of the unit.
+func CostEfficiency(up *types.UnitProperties) (dps float64, health float64) {
+	if up.MetalCost == 0 {
+		return 0, 0
+	}
+	return up.DPS() / float64(up.MetalCost) * 100, float64(up.Health) / float64(up.MetalCost) * 100
+}
+
+// TimeToKill returns the seconds attacker needs to destroy target, or zero
+// when the attacker deals no damage.
+func TimeToKill(attacker *types.UnitProperties, target *types.UnitProperties) float64 {
+	dps := attacker.DPS()
+	if dps == 0 {
+		return 0
+	}
+	return float64(target.Health) / dps
+}
@@ -0,0 +1,229 @@
+package util
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+const (
+	SearchFieldRef         = "ref"
+	SearchFieldName        = "name"
+	SearchFieldAlias       = "alias"
+	SearchFieldDescription = "description"
+	SearchFieldUnitGroup   = "unit group"
+	SearchFieldWeapon      = "weapon"
+)
+
+// searchFieldWeights ranks matches in refs, names and aliases above the
+// others.
+var searchFieldWeights = map[string]float64{
+	SearchFieldRef:         4,
+	SearchFieldName:        4,
+	SearchFieldAlias:       4,
+	SearchFieldUnitGroup:   2,
+	SearchFieldWeapon:      2,
+	SearchFieldDescription: 1,
+}
+
+// prefixMatchWeight scales the score of a term that only matches the start of
+// a word, so units matching whole words rank first.
+const prefixMatchWeight = 0.5
+
+// SearchMatch is a text of a unit containing a search term.
+type SearchMatch struct {
+	Field string
+	Text  string
+}
+
+type SearchResult struct {
+	Ref     types.UnitRef
+	Score   float64
+	Matches []SearchMatch
+	// Terms are the query terms, for highlighting the matches
+	Terms []string
+}
+
+type searchPosting struct {
+	ref   types.UnitRef
+	field string
+	text  string
+}
+
+// SearchIndex is an inverted index from the words of unit texts to the units
+// containing them.
+type SearchIndex struct {
+	// words holds the indexed words sorted, to find words by prefix
+	words    []string
+	postings map[string][]searchPosting
+}
+
+// NewSearchIndex indexes the refs, translated names, aliases, descriptions,
+// unit groups and weapon names of refs.
+func NewSearchIndex(refs []types.UnitRef) *SearchIndex {
+	idx := &SearchIndex{postings: make(map[string][]searchPosting)}
+	for _, ref := range refs {
+		idx.add(ref, SearchFieldRef, ref)
+		idx.add(ref, SearchFieldName, NameForRef(ref))
+		for _, alias := range AliasesForRef(ref) {
+			idx.add(ref, SearchFieldAlias, alias)
+		}
+		idx.add(ref, SearchFieldDescription, DescriptionForRef(ref))
+		up, ok := gamedata.GetUnitPropertiesByRef(ref)
+		if !ok {
+			continue
+		}
+		idx.add(ref, SearchFieldUnitGroup, up.CustomParams.UnitGroup)
+		names := make(map[string]bool)
+		for _, wd := range up.WeaponDefs {
+			if wd.Name != "" && !names[wd.Name] {
+				names[wd.Name] = true
+				idx.add(ref, SearchFieldWeapon, wd.Name)
+			}
+		}
+	}
+	for word := range idx.postings {
+		idx.words = append(idx.words, word)
+	}
+	sort.Strings(idx.words)
+	return idx
+}
+
+func (idx *SearchIndex) add(ref types.UnitRef, field string, text string) {
+	seen := make(map[string]bool)
+	for _, word := range Tokenize(text) {
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		idx.postings[word] = append(idx.postings[word], searchPosting{ref, field, text})
+	}
+}
+
+// Search returns the units matching every word of query, best matches first.
+// The last word may be incomplete, every word matches the start of indexed
+// words so results update while typing.
+func (idx *SearchIndex) Search(query string) []SearchResult {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	scores := make(map[types.UnitRef]float64)
+	matches := make(map[types.UnitRef]map[SearchMatch]bool)
+	for i, term := range terms {
+		termScores := make(map[types.UnitRef]float64)
+		for j := sort.SearchStrings(idx.words, term); j < len(idx.words) && strings.HasPrefix(idx.words[j], term); j++ {
+			word := idx.words[j]
+			for _, p := range idx.postings[word] {
+				if i > 0 {
+					if _, ok := scores[p.ref]; !ok {
+						continue
+					}
+				}
+				score := searchFieldWeights[p.field]
+				if word != term {
+					score *= prefixMatchWeight
+				}
+				termScores[p.ref] = max(termScores[p.ref], score)
+				if matches[p.ref] == nil {
+					matches[p.ref] = make(map[SearchMatch]bool)
+				}
+				matches[p.ref][SearchMatch{p.field, p.text}] = true
+			}
+		}
+		// Units have to match every term
+		next := make(map[types.UnitRef]float64)
+		for ref, score := range termScores {
+			next[ref] = scores[ref] + score
+		}
+		scores = next
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for ref, score := range scores {
+		r := SearchResult{Ref: ref, Score: score, Terms: terms}
+		for m := range matches[ref] {
+			r.Matches = append(r.Matches, m)
+		}
+		sort.Slice(r.Matches, func(i, j int) bool {
+			a, b := r.Matches[i], r.Matches[j]
+			if searchFieldWeights[a.Field] != searchFieldWeights[b.Field] {
+				return searchFieldWeights[a.Field] > searchFieldWeights[b.Field]
+			}
+			return a.Text < b.Text
+		})
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Ref < results[j].Ref
+	})
+	return results
+}
+
+var searchIndex *SearchIndex
+
+// SearchUnits searches every buildable unit, PvE units are only included with
+// includePvE.
+func SearchUnits(query string, includePvE bool) []SearchResult {
+	if searchIndex == nil {
+		searchIndex = NewSearchIndex(BuildableUnits(true))
+	}
+	results := make([]SearchResult, 0)
+	for _, r := range searchIndex.Search(query) {
+		if up, ok := gamedata.GetUnitPropertiesByRef(r.Ref); ok && up.IsPvE() && !includePvE {
+			continue
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Tokenize splits text into lowercase words.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !isWordRune(r)
+	})
+}
+
+// HighlightTerms passes the start of every word in text matching one of terms
+// through highlight.
+func HighlightTerms(text string, terms []string, highlight func(string) string) string {
+	runes := []rune(text)
+	b := strings.Builder{}
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		end := i
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		word := runes[i:end]
+		matched := 0
+		for _, term := range terms {
+			t := []rune(term)
+			if len(t) > matched && len(t) <= len(word) && strings.EqualFold(string(word[:len(t)]), term) {
+				matched = len(t)
+			}
+		}
+		if matched > 0 {
+			b.WriteString(highlight(string(word[:matched])))
+		}
+		b.WriteString(string(word[matched:]))
+		i = end
+	}
+	return b.String()
+}
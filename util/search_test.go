@@ -0,0 +1,144 @@
+package util
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+// newTestSearchIndex indexes the given field texts per ref without the game
+// data NewSearchIndex reads.
+func newTestSearchIndex(texts map[types.UnitRef]map[string]string) *SearchIndex {
+	idx := &SearchIndex{postings: make(map[string][]searchPosting)}
+	for ref, fields := range texts {
+		for field, text := range fields {
+			idx.add(ref, field, text)
+		}
+	}
+	for word := range idx.postings {
+		idx.words = append(idx.words, word)
+	}
+	sort.Strings(idx.words)
+	return idx
+}
+
+func TestSearchIndexSearch(t *testing.T) {
+	idx := newTestSearchIndex(map[types.UnitRef]map[string]string{
+		"armpw": {
+			SearchFieldRef:         "armpw",
+			SearchFieldName:        "Pawn",
+			SearchFieldDescription: "Fast Infantry Bot",
+			SearchFieldUnitGroup:   "weapon",
+		},
+		"armflea": {
+			SearchFieldRef:         "armflea",
+			SearchFieldName:        "Tick",
+			SearchFieldDescription: "Fast Scout Bot",
+		},
+		"corak": {
+			SearchFieldRef:         "corak",
+			SearchFieldName:        "Grunt",
+			SearchFieldDescription: "Light Infantry Bot",
+			SearchFieldWeapon:      "Pawnshop Laser",
+		},
+		"armfast": {
+			SearchFieldRef:         "armfast",
+			SearchFieldName:        "Sprinter",
+			SearchFieldDescription: "Fast Assault Bot",
+		},
+	})
+	tests := []struct {
+		query string
+		want  []types.UnitRef
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"pawn", []types.UnitRef{"armpw", "corak"}},
+		{"PAW", []types.UnitRef{"armpw", "corak"}},
+		{"fast", []types.UnitRef{"armfast", "armflea", "armpw"}},
+		{"infantry", []types.UnitRef{"armpw", "corak"}},
+		{"fast infantry", []types.UnitRef{"armpw"}},
+		{"bot light", []types.UnitRef{"corak"}},
+		{"bot", []types.UnitRef{"armfast", "armflea", "armpw", "corak"}},
+		{"tick", []types.UnitRef{"armflea"}},
+		{"missing", []types.UnitRef{}},
+		{"fast missing", []types.UnitRef{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results := idx.Search(tt.query)
+			var got []types.UnitRef
+			if results != nil {
+				got = make([]types.UnitRef, 0)
+			}
+			for _, r := range results {
+				got = append(got, r.Ref)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchIndexSearchMatches(t *testing.T) {
+	idx := newTestSearchIndex(map[types.UnitRef]map[string]string{
+		"armpw": {
+			SearchFieldRef:         "armpw",
+			SearchFieldName:        "Pawn",
+			SearchFieldDescription: "Pawn like Infantry Bot",
+		},
+	})
+	results := idx.Search("pawn")
+	if len(results) != 1 {
+		t.Fatalf("Search() returned %d results, want 1", len(results))
+	}
+	want := []SearchMatch{
+		{SearchFieldName, "Pawn"},
+		{SearchFieldDescription, "Pawn like Infantry Bot"},
+	}
+	if !reflect.DeepEqual(results[0].Matches, want) {
+		t.Errorf("Matches = %v, want %v", results[0].Matches, want)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"Fast Infantry Bot", []string{"fast", "infantry", "bot"}},
+		{"T2 Anti-Air (Flak)", []string{"t2", "anti", "air", "flak"}},
+		{"armpw_scav", []string{"armpw", "scav"}},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightTerms(t *testing.T) {
+	upper := strings.ToUpper
+	tests := []struct {
+		text  string
+		terms []string
+		want  string
+	}{
+		{"Fast Infantry Bot", []string{"inf"}, "Fast INFantry Bot"},
+		{"Fast Infantry Bot", []string{"f", "fast"}, "FAST Infantry Bot"},
+		{"Anti-Air Flak", []string{"air"}, "Anti-AIR Flak"},
+		{"Pawn", []string{"awn"}, "Pawn"},
+		{"Pawn", []string{"pawnshop"}, "Pawn"},
+		{"Pawn", nil, "Pawn"},
+	}
+	for _, tt := range tests {
+		if got := HighlightTerms(tt.text, tt.terms, upper); got != tt.want {
+			t.Errorf("HighlightTerms(%q, %v) = %q, want %q", tt.text, tt.terms, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"sort"
+
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+// TranslationCoverage is the state of the unit translations of one language
+// compared with gamedata.DefaultLanguage.
+type TranslationCoverage struct {
+	Language            string          `json:"language"`
+	MissingNames        []types.UnitRef `json:"missingNames"`
+	MissingDescriptions []types.UnitRef `json:"missingDescriptions"`
+	// Orphaned lists translated refs without a unit definition
+	Orphaned []types.UnitRef `json:"orphaned"`
+	// Coverage is the percentage of the default language's names and
+	// descriptions that are translated
+	Coverage float64 `json:"coverage"`
+}
+
+// TranslationCoverageReport returns the coverage of every generated language.
+func TranslationCoverageReport() []TranslationCoverage {
+	fallback, _ := gamedata.GetLanguageTranslations(gamedata.DefaultLanguage)
+	units := gamedata.GetUnitProperties()
+
+	report := make([]TranslationCoverage, 0)
+	for _, lang := range gamedata.Languages() {
+		t, _ := gamedata.GetLanguageTranslations(lang)
+		c := TranslationCoverage{
+			Language:            lang,
+			MissingNames:        missingKeys(t.Units.Names, fallback.Units.Names),
+			MissingDescriptions: missingKeys(t.Units.Descriptions, fallback.Units.Descriptions),
+			Orphaned:            make([]types.UnitRef, 0),
+			Coverage:            100,
+		}
+
+		orphaned := make(map[types.UnitRef]bool)
+		for _, m := range []map[types.UnitRef]string{t.Units.Names, t.Units.Descriptions} {
+			for ref := range m {
+				if _, ok := units[ref]; !ok {
+					orphaned[ref] = true
+				}
+			}
+		}
+		for ref := range orphaned {
+			c.Orphaned = append(c.Orphaned, ref)
+		}
+		sort.Strings(c.Orphaned)
+
+		if total := countTranslated(fallback.Units.Names) + countTranslated(fallback.Units.Descriptions); total > 0 {
+			missing := len(c.MissingNames) + len(c.MissingDescriptions)
+			c.Coverage = float64(total-missing) / float64(total) * 100
+		}
+		report = append(report, c)
+	}
+	return report
+}
+
+// missingKeys returns the sorted keys translated in fallback but not in m.
+func missingKeys(m map[types.UnitRef]string, fallback map[types.UnitRef]string) []types.UnitRef {
+	missing := make([]types.UnitRef, 0)
+	for ref, v := range fallback {
+		if v != "" && m[ref] == "" {
+			missing = append(missing, ref)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func countTranslated(m map[types.UnitRef]string) int {
+	count := 0
+	for _, v := range m {
+		if v != "" {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+func TestMissingKeys(t *testing.T) {
+	fallback := map[types.UnitRef]string{"armpw": "Pawn", "corak": "Grunt", "armflea": "Tick", "legcom": ""}
+	tests := []struct {
+		name string
+		m    map[types.UnitRef]string
+		want []types.UnitRef
+	}{
+		{"complete", map[types.UnitRef]string{"armpw": "Pion", "corak": "Grunt", "armflea": "Tique"}, []types.UnitRef{}},
+		{"empty", nil, []types.UnitRef{"armflea", "armpw", "corak"}},
+		{"blank values", map[types.UnitRef]string{"armpw": "", "corak": "Grunt", "armflea": "Tique"}, []types.UnitRef{"armpw"}},
+		{"extra keys", map[types.UnitRef]string{"armpw": "Pion", "corak": "Grunt", "armflea": "Tique", "armmex": "Extracteur"}, []types.UnitRef{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingKeys(tt.m, fallback); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missingKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountTranslated(t *testing.T) {
+	tests := []struct {
+		m    map[types.UnitRef]string
+		want int
+	}{
+		{nil, 0},
+		{map[types.UnitRef]string{"armpw": "Pawn", "corak": ""}, 1},
+		{map[types.UnitRef]string{"armpw": "Pawn", "corak": "Grunt"}, 2},
+	}
+	for _, tt := range tests {
+		if got := countTranslated(tt.m); got != tt.want {
+			t.Errorf("countTranslated(%v) = %d, want %d", tt.m, got, tt.want)
+		}
+	}
+}
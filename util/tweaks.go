@@ -0,0 +1,173 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/wezzle/bar-unit-info/gamedata"
+	"github.com/wezzle/bar-unit-info/gamedata/parser"
+	"github.com/wezzle/bar-unit-info/gamedata/types"
+)
+
+const TweakedMarker = "*"
+
+// tweakDiffs caches the result of TweakDiff by ref, the table marks tweaked
+// values in every cell.
+var tweakDiffs = make(map[types.UnitRef][]types.PropertyDiff)
+
+// TweakDiff returns the differences between the stock and tweaked properties
+// of ref.
+func TweakDiff(ref types.UnitRef) []types.PropertyDiff {
+	if diff, ok := tweakDiffs[ref]; ok {
+		return diff
+	}
+	tweaked, ok := gamedata.GetTweakedUnitPropertiesByRef(ref)
+	if !ok {
+		tweakDiffs[ref] = nil
+		return nil
+	}
+	stock, ok := gamedata.GetStockUnitPropertiesByRef(ref)
+	if !ok {
+		stock = &types.UnitProperties{Ref: ref}
+	}
+	tweakDiffs[ref] = types.DiffUnitProperties(stock, tweaked)
+	return tweakDiffs[ref]
+}
+
+// LoadTweaks applies the tweakdefs and tweakunits modoptions in mo to the
+// stock unit definitions and replaces the tweaked data with the result. The
+// tweaks are applied after the game's post-processing. It returns the number
+// of units changed.
+func LoadTweaks(mo types.ModOptions) (int, error) {
+	moveDefs := make(map[string]types.MoveDef)
+	for _, ref := range AllRefs() {
+		if up, ok := gamedata.GetStockUnitPropertiesByRef(ref); ok && up.Movement.MovementClass != "" {
+			moveDefs[up.Movement.MovementClass] = up.Movement.MoveDef
+		}
+	}
+	unitProperties, err := parser.TweakUnitDefs(gamedata.GetRawUnitDefs(), mo, moveDefs)
+	if err != nil {
+		return 0, err
+	}
+
+	tweaked := make(map[string]types.UnitProperties)
+	for _, up := range unitProperties {
+		stock, ok := gamedata.GetStockUnitPropertiesByRef(up.Ref)
+		if !ok {
+			continue
+		}
+		// The tech level of lab built units is taken from their lab
+		if up.CustomParams.TechLevel == 0 {
+			up.CustomParams.TechLevel = stock.CustomParams.TechLevel
+		}
+		if len(types.DiffUnitProperties(stock, &up)) > 0 {
+			tweaked[up.Ref] = up
+		}
+	}
+	gamedata.SetTweakedUnitProperties(tweaked)
+	tweakDiffs = make(map[types.UnitRef][]types.PropertyDiff)
+	return len(tweaked), nil
+}
+
+// TweakModOptions turns base64 encoded tweaks as pasted in a lobby into
+// tweakdefs and tweakunits modoptions, numbered in the order they're given.
+func TweakModOptions(encoded []string) (types.ModOptions, error) {
+	mo := make(types.ModOptions)
+	defs, units := 0, 0
+	for _, e := range encoded {
+		decoded, err := parser.DecodeTweak(strings.TrimSpace(e))
+		if err != nil {
+			return nil, err
+		}
+		if parser.IsTweakUnits(decoded) {
+			mo[tweakKey("tweakunits", units)] = strings.TrimSpace(e)
+			units++
+		} else {
+			mo[tweakKey("tweakdefs", defs)] = strings.TrimSpace(e)
+			defs++
+		}
+	}
+	if defs > 10 || units > 10 {
+		return nil, fmt.Errorf("at most 10 tweakdefs and 10 tweakunits are supported")
+	}
+	return mo, nil
+}
+
+// ReadTweaksFile reads tweaks from a JSON object of modoptions, of which only
+// the tweakdefs and tweakunits are used, or from a file with one base64
+// encoded tweak per line.
+func ReadTweaksFile(path string) (types.ModOptions, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(content)), "{") {
+		mo, err := parser.LoadModOptionsFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for k := range mo {
+			if !strings.HasPrefix(k, "tweakdefs") && !strings.HasPrefix(k, "tweakunits") {
+				delete(mo, k)
+			}
+		}
+		return mo, nil
+	}
+	return TweakModOptions(strings.Fields(string(content)))
+}
+
+// tweakKey returns the modoption of the i-th tweak, tweakdefs, tweakdefs1 and
+// so on.
+func tweakKey(prefix string, i int) string {
+	if i == 0 {
+		return prefix
+	}
+	return fmt.Sprintf("%s%d", prefix, i)
+}
+
+// IsFieldTweaked reports whether the property at path, or a property nested
+// under it, was changed by tweaks and the tweaked data is in use.
+func IsFieldTweaked(ref types.UnitRef, path string) bool {
+	if !gamedata.IsUsingTweakedUnitProperties() {
+		return false
+	}
+	for _, d := range TweakDiff(ref) {
+		if d.Path == path || strings.HasPrefix(d.Path, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkTweaked appends TweakedMarker to value when the property at path was
+// changed by tweaks.
+func MarkTweaked(ref types.UnitRef, path string, value string) string {
+	if IsFieldTweaked(ref, path) {
+		return value + TweakedMarker
+	}
+	return value
+}
+
+// UnmarkTweaked returns a copy of record with TweakedMarker removed from every
+// value, for output that is read by other tools.
+func UnmarkTweaked(record []string) []string {
+	unmarked := make([]string, len(record))
+	for i, v := range record {
+		unmarked[i] = strings.TrimSuffix(v, TweakedMarker)
+	}
+	return unmarked
+}
+
+var TweakHeader = []string{"Ref", "Name", "Property", "Stock", "Tweaked"}
+
+// TweakRecords lists every property changed by tweaks, matching TweakHeader.
+func TweakRecords() [][]string {
+	records := make([][]string, 0)
+	for _, ref := range AllRefs() {
+		for _, d := range TweakDiff(ref) {
+			records = append(records, []string{ref, NameForRef(ref), d.Path, d.Old, d.New})
+		}
+	}
+	return records
+}